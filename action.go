@@ -0,0 +1,249 @@
+package gocto
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+
+	"github.com/cakehappens/gocto/expressions"
+)
+
+const (
+	DefaultPathToActions = "./.github/actions"
+	ActionFilename       = "action.yml"
+)
+
+// Action
+// https://docs.github.com/en/actions/reference/metadata-syntax-for-github-actions
+type Action struct {
+	Name        string                  `json:"name"`
+	Author      string                  `json:"author,omitempty,omitzero"`
+	Description string                  `json:"description"`
+	Inputs      map[string]ActionInput  `json:"inputs,omitempty,omitzero"`
+	Outputs     map[string]ActionOutput `json:"outputs,omitempty,omitzero"`
+	Runs        ActionRuns              `json:"runs"`
+	Branding    Branding                `json:"branding,omitempty,omitzero"`
+	// Storing the directory name here is useful when you need to reference the action from a workflow
+	dirname string
+}
+
+type ActionInput struct {
+	Description        string `json:"description"`
+	Required           *bool  `json:"required,omitempty,omitzero"`
+	Default            string `json:"default,omitempty,omitzero"`
+	DeprecationMessage string `json:"deprecationMessage,omitempty,omitzero"`
+}
+
+type ActionOutput struct {
+	Description string `json:"description"`
+	// Value is required for composite actions, and ignored otherwise
+	Value string `json:"value,omitempty,omitzero"`
+}
+
+type ActionRunsUsing string
+
+const (
+	ActionRunsUsingComposite ActionRunsUsing = "composite"
+	ActionRunsUsingNode20    ActionRunsUsing = "node20"
+	ActionRunsUsingDocker    ActionRunsUsing = "docker"
+)
+
+// ActionRuns
+// https://docs.github.com/en/actions/reference/metadata-syntax-for-github-actions#runs
+type ActionRuns struct {
+	Using ActionRunsUsing `json:"using"`
+	// Steps are only used by composite actions
+	Steps []Step `json:"steps,omitempty,omitzero"`
+	// Main, Pre, PreIf, Post and PostIf are only used by node actions
+	Main   string `json:"main,omitempty,omitzero"`
+	Pre    string `json:"pre,omitempty,omitzero"`
+	PreIf  string `json:"pre-if,omitempty,omitzero"`
+	Post   string `json:"post,omitempty,omitzero"`
+	PostIf string `json:"post-if,omitempty,omitzero"`
+	// Image, Env, Args and the entrypoints are only used by docker actions
+	Image          string            `json:"image,omitempty,omitzero"`
+	Env            map[string]string `json:"env,omitempty,omitzero"`
+	Args           []string          `json:"args,omitempty,omitzero"`
+	PreEntrypoint  string            `json:"pre-entrypoint,omitempty,omitzero"`
+	Entrypoint     string            `json:"entrypoint,omitempty,omitzero"`
+	PostEntrypoint string            `json:"post-entrypoint,omitempty,omitzero"`
+}
+
+func CompositeRuns(steps ...Step) ActionRuns {
+	return ActionRuns{
+		Using: ActionRunsUsingComposite,
+		Steps: steps,
+	}
+}
+
+func NodeRuns(main string) ActionRuns {
+	return ActionRuns{
+		Using: ActionRunsUsingNode20,
+		Main:  main,
+	}
+}
+
+func DockerRuns(image string, args ...string) ActionRuns {
+	return ActionRuns{
+		Using: ActionRunsUsingDocker,
+		Image: image,
+		Args:  args,
+	}
+}
+
+// Branding
+// https://docs.github.com/en/actions/reference/metadata-syntax-for-github-actions#branding
+type Branding struct {
+	Icon  string        `json:"icon,omitempty,omitzero"`
+	Color BrandingColor `json:"color,omitempty,omitzero"`
+}
+
+type BrandingColor string
+
+const (
+	BrandingColorWhite    BrandingColor = "white"
+	BrandingColorBlack    BrandingColor = "black"
+	BrandingColorYellow   BrandingColor = "yellow"
+	BrandingColorBlue     BrandingColor = "blue"
+	BrandingColorGreen    BrandingColor = "green"
+	BrandingColorOrange   BrandingColor = "orange"
+	BrandingColorRed      BrandingColor = "red"
+	BrandingColorPurple   BrandingColor = "purple"
+	BrandingColorGrayDark BrandingColor = "gray-dark"
+)
+
+func (a *Action) SetDirname(value string) {
+	if a == nil {
+		return
+	}
+
+	a.dirname = value
+}
+
+func (a *Action) GetDirname() string {
+	if a == nil {
+		return ""
+	}
+
+	if a.dirname == "" {
+		a.dirname = slugFor(a.Name)
+	}
+
+	return a.dirname
+}
+
+// GetRelativePath is the directory of the action, in the form Step.Uses expects
+func (a *Action) GetRelativePath() string {
+	return "./" + path.Join(DefaultPathToActions, a.GetDirname())
+}
+
+func (a *Action) GetRelativePathAndFilename() string {
+	return path.Join(a.GetRelativePath(), ActionFilename)
+}
+
+// Step builds a step using this action.
+// The inputs are checked against the declared inputs, unknown inputs
+// and required inputs without a default are an error
+func (a *Action) Step(with map[string]any) (Step, error) {
+	var errs []error
+	for _, k := range slices.Sorted(maps.Keys(with)) {
+		if _, ok := a.Inputs[k]; !ok {
+			errs = append(errs, fmt.Errorf("action %q has no input %q", a.Name, k))
+		}
+	}
+
+	for _, k := range slices.Sorted(maps.Keys(a.Inputs)) {
+		input := a.Inputs[k]
+		if _, ok := with[k]; !ok && input.Required != nil && *input.Required && input.Default == "" {
+			errs = append(errs, fmt.Errorf("action %q requires input %q", a.Name, k))
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return Step{}, err
+	}
+
+	return Step{
+		Uses: a.GetRelativePath(),
+		With: with,
+	}, nil
+}
+
+// Output references an output of this action, used by the step with the given ID
+func (a *Action) Output(stepID, name string) (expressions.Expression, error) {
+	if _, ok := a.Outputs[name]; !ok {
+		return "", fmt.Errorf("action %q has no output %q", a.Name, name)
+	}
+
+	return expressions.StepOutput(stepID, name), nil
+}
+
+// Validate checks the fields required by the kind of action in Runs
+func (a *Action) Validate() error {
+	var errs []error
+	if a.Name == "" {
+		errs = append(errs, errors.New("action name is required"))
+	}
+
+	if a.Description == "" {
+		errs = append(errs, fmt.Errorf("action %q: description is required", a.Name))
+	}
+
+	switch a.Runs.Using {
+	case ActionRunsUsingComposite:
+		if len(a.Runs.Steps) == 0 {
+			errs = append(errs, fmt.Errorf("action %q: composite actions require steps", a.Name))
+		}
+
+		for i, s := range a.Runs.Steps {
+			if s.Run != "" && s.Shell == "" {
+				errs = append(errs, fmt.Errorf("action %q: Runs.Steps[%d]: run steps in composite actions require a shell", a.Name, i))
+			}
+		}
+
+		for _, k := range slices.Sorted(maps.Keys(a.Outputs)) {
+			if a.Outputs[k].Value == "" {
+				errs = append(errs, fmt.Errorf("action %q: output %q requires a value in composite actions", a.Name, k))
+			}
+		}
+	case ActionRunsUsingNode20:
+		if a.Runs.Main == "" {
+			errs = append(errs, fmt.Errorf("action %q: node actions require main", a.Name))
+		}
+	case ActionRunsUsingDocker:
+		if a.Runs.Image == "" {
+			errs = append(errs, fmt.Errorf("action %q: docker actions require an image", a.Name))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("action %q: unknown runs.using %q", a.Name, a.Runs.Using))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (a *Action) Render() ([]byte, error) {
+	return MarshalYAML(a)
+}
+
+// WriteFile validates and writes the action to GetRelativePathAndFilename under root
+func (a *Action) WriteFile(root string) error {
+	if err := a.Validate(); err != nil {
+		return err
+	}
+
+	doc, err := a.Render()
+	if err != nil {
+		return err
+	}
+
+	filename := filepath.Join(root, filepath.FromSlash(a.GetRelativePathAndFilename()))
+	if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filename, doc, 0o644)
+}
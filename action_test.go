@@ -0,0 +1,90 @@
+package gocto
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cakehappens/gocto/expressions"
+)
+
+func testCompositeAction() *Action {
+	return &Action{
+		Name:        "Setup Toolchain",
+		Description: "installs the toolchain",
+		Inputs: map[string]ActionInput{
+			"version": {
+				Description: "toolchain version",
+				Required:    Ptr(true),
+			},
+			"legacy": {
+				Description:        "unused",
+				DeprecationMessage: "legacy is ignored",
+			},
+		},
+		Outputs: map[string]ActionOutput{
+			"path": {
+				Description: "install path",
+				Value:       expressions.StepOutput("install", "path").String(),
+			},
+		},
+		Runs: CompositeRuns(
+			Step{
+				ID:    "install",
+				Run:   "./install.sh \"$VERSION\"\necho \"path=$PWD/bin\" >> \"$GITHUB_OUTPUT\"",
+				Shell: ShellBash,
+				Env: map[string]string{
+					"VERSION": expressions.Inputs("version").String(),
+				},
+			},
+		),
+		Branding: Branding{
+			Icon:  "tool",
+			Color: BrandingColorBlue,
+		},
+	}
+}
+
+func TestActionWriteFile(t *testing.T) {
+	a := testCompositeAction()
+	root := t.TempDir()
+	require.NoError(t, a.WriteFile(root))
+
+	doc, err := os.ReadFile(filepath.Join(root, ".github", "actions", "setup-toolchain", "action.yml"))
+	require.NoError(t, err)
+
+	assert.Contains(t, string(doc), "deprecationMessage: legacy is ignored\n")
+	assert.Contains(t, string(doc), "value: ${{steps.install.outputs.path}}\n")
+	assert.Contains(t, string(doc), "using: composite\n")
+	assert.Contains(t, string(doc), "run: |-\n")
+}
+
+func TestActionValidate(t *testing.T) {
+	a := testCompositeAction()
+	a.Runs.Steps[0].Shell = ""
+	assert.ErrorContains(t, a.Validate(), "require a shell")
+
+	a = &Action{Name: "node", Description: "node", Runs: NodeRuns("")}
+	assert.ErrorContains(t, a.Validate(), "require main")
+}
+
+func TestActionStep(t *testing.T) {
+	a := testCompositeAction()
+
+	s, err := a.Step(map[string]any{"version": "1.2.3"})
+	require.NoError(t, err)
+	assert.Equal(t, "./.github/actions/setup-toolchain", s.Uses)
+
+	_, err = a.Step(nil)
+	assert.ErrorContains(t, err, `requires input "version"`)
+
+	_, err = a.Step(map[string]any{"version": "1", "nope": true})
+	assert.ErrorContains(t, err, `has no input "nope"`)
+
+	out, err := a.Output("toolchain", "path")
+	require.NoError(t, err)
+	assert.Equal(t, expressions.StepOutput("toolchain", "path"), out)
+}
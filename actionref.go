@@ -0,0 +1,140 @@
+package gocto
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type ActionRefKind string
+
+const (
+	// ActionRefRemote is an action or reusable workflow in a public repository, owner/repo/path@ref
+	ActionRefRemote ActionRefKind = "remote"
+	// ActionRefLocal is an action or reusable workflow in the same repository, ./path
+	ActionRefLocal ActionRefKind = "local"
+	// ActionRefDocker is a container image on a registry, docker://image:tag
+	ActionRefDocker ActionRefKind = "docker"
+)
+
+const dockerRefPrefix = "docker://"
+
+// ActionRef is a parsed Step.Uses or Job.Uses value
+// https://docs.github.com/en/actions/reference/workflow-syntax-for-github-actions#jobsjob_idstepsuses
+type ActionRef struct {
+	Kind  ActionRefKind
+	Owner string
+	Repo  string
+	// Path is the directory of the action within the repository, or the reusable workflow file.
+	// For local references it is the whole path, including the leading ./
+	Path string
+	// Ref is the branch, tag or commit SHA of a remote reference
+	Ref string
+	// Image is the image of a docker reference, including any tag or digest
+	Image string
+}
+
+func ParseActionRef(uses string) (ActionRef, error) {
+	switch {
+	case uses == "":
+		return ActionRef{}, errors.New("empty action reference")
+	case strings.HasPrefix(uses, "./"):
+		return ActionRef{Kind: ActionRefLocal, Path: uses}, nil
+	case strings.HasPrefix(uses, dockerRefPrefix):
+		image := strings.TrimPrefix(uses, dockerRefPrefix)
+		if image == "" {
+			return ActionRef{}, fmt.Errorf("missing image in action reference %q", uses)
+		}
+		return ActionRef{Kind: ActionRefDocker, Image: image}, nil
+	}
+
+	name, ref, ok := strings.Cut(uses, "@")
+	if !ok || ref == "" {
+		return ActionRef{}, fmt.Errorf("missing @ref in action reference %q", uses)
+	}
+
+	parts := strings.SplitN(name, "/", 3)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return ActionRef{}, fmt.Errorf("expected owner/repo in action reference %q", uses)
+	}
+
+	r := ActionRef{
+		Kind:  ActionRefRemote,
+		Owner: parts[0],
+		Repo:  parts[1],
+		Ref:   ref,
+	}
+
+	if len(parts) == 3 {
+		r.Path = parts[2]
+	}
+
+	return r, nil
+}
+
+func (r ActionRef) String() string {
+	switch r.Kind {
+	case ActionRefLocal:
+		return r.Path
+	case ActionRefDocker:
+		return dockerRefPrefix + r.Image
+	}
+
+	name := r.Repository()
+	if r.Path != "" {
+		name += "/" + r.Path
+	}
+
+	return name + "@" + r.Ref
+}
+
+// Repository is owner/repo for remote references, and empty otherwise
+func (r ActionRef) Repository() string {
+	if r.Kind != ActionRefRemote {
+		return ""
+	}
+
+	return r.Owner + "/" + r.Repo
+}
+
+// IsPinned reports whether the reference is immutable:
+// a full length commit SHA for remote references, or a digest for docker references.
+// Local references are always considered pinned since they are versioned with the workflow.
+func (r ActionRef) IsPinned() bool {
+	switch r.Kind {
+	case ActionRefLocal:
+		return true
+	case ActionRefDocker:
+		return strings.Contains(r.Image, "@sha256:")
+	}
+
+	return IsCommitSHA(r.Ref)
+}
+
+func (r ActionRef) WithRef(ref string) ActionRef {
+	r.Ref = ref
+	return r
+}
+
+// IsCommitSHA reports whether val is a full length, lowercase hex, commit SHA
+func IsCommitSHA(val string) bool {
+	if len(val) != 40 {
+		return false
+	}
+
+	for _, r := range val {
+		if !('0' <= r && r <= '9' || 'a' <= r && r <= 'f') {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s Step) ActionRef() (ActionRef, error) {
+	return ParseActionRef(s.Uses)
+}
+
+func (j Job) ActionRef() (ActionRef, error) {
+	return ParseActionRef(j.Uses)
+}
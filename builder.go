@@ -0,0 +1,264 @@
+package gocto
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/cakehappens/gocto/expressions"
+)
+
+// NewWorkflow starts a workflow for the builder methods. Like the With methods of Step,
+// they return a modified copy, so a partially built Workflow or Job can be reused as a template:
+//
+//	gocto.NewWorkflow("CI").
+//		OnPush(gocto.Branches("main")).
+//		OnPullRequest().
+//		Job("build", gocto.NewJob("ubuntu-latest").WithSteps(actions.Checkout{}.Step(), gocto.Step{Run: "make"}))
+func NewWorkflow(name string) Workflow {
+	return Workflow{Name: name}
+}
+
+// PushFilter is a filter of the push trigger
+type PushFilter interface {
+	applyPush(on *OnPush)
+}
+
+// PullRequestFilter is a filter of the pull_request and pull_request_target triggers
+type PullRequestFilter interface {
+	applyPullRequest(on *OnPullRequest)
+}
+
+// WorkflowRunFilter is a filter of the workflow_run trigger
+type WorkflowRunFilter interface {
+	applyWorkflowRun(on *OnWorkflowRun)
+}
+
+type BranchFilter OnBranches
+
+func Branches(patterns ...string) BranchFilter {
+	return BranchFilter{Branches: patterns}
+}
+
+func BranchesIgnore(patterns ...string) BranchFilter {
+	return BranchFilter{BranchesIgnore: patterns}
+}
+
+func (f BranchFilter) apply(on **OnBranches) {
+	if *on == nil {
+		*on = &OnBranches{}
+	}
+
+	(*on).Branches = append((*on).Branches, f.Branches...)
+	(*on).BranchesIgnore = append((*on).BranchesIgnore, f.BranchesIgnore...)
+}
+
+func (f BranchFilter) applyPush(on *OnPush)               { f.apply(&on.OnBranches) }
+func (f BranchFilter) applyPullRequest(on *OnPullRequest) { f.apply(&on.OnBranches) }
+func (f BranchFilter) applyWorkflowRun(on *OnWorkflowRun) { f.apply(&on.OnBranches) }
+
+type TagFilter OnTags
+
+func Tags(patterns ...string) TagFilter {
+	return TagFilter{Tags: patterns}
+}
+
+func TagsIgnore(patterns ...string) TagFilter {
+	return TagFilter{TagsIgnore: patterns}
+}
+
+func (f TagFilter) applyPush(on *OnPush) {
+	if on.OnTags == nil {
+		on.OnTags = &OnTags{}
+	}
+
+	on.Tags = append(on.Tags, f.Tags...)
+	on.TagsIgnore = append(on.TagsIgnore, f.TagsIgnore...)
+}
+
+type PathFilter OnPaths
+
+func Paths(patterns ...string) PathFilter {
+	return PathFilter{Paths: patterns}
+}
+
+func PathsIgnore(patterns ...string) PathFilter {
+	return PathFilter{PathsIgnore: patterns}
+}
+
+func (f PathFilter) apply(on **OnPaths) {
+	if *on == nil {
+		*on = &OnPaths{}
+	}
+
+	(*on).Paths = append((*on).Paths, f.Paths...)
+	(*on).PathsIgnore = append((*on).PathsIgnore, f.PathsIgnore...)
+}
+
+func (f PathFilter) applyPush(on *OnPush)               { f.apply(&on.OnPaths) }
+func (f PathFilter) applyPullRequest(on *OnPullRequest) { f.apply(&on.OnPaths) }
+
+func (w Workflow) OnPush(filters ...PushFilter) Workflow {
+	on := &OnPush{}
+	for _, f := range filters {
+		f.applyPush(on)
+	}
+
+	w.On.Push = on
+	return w
+}
+
+func (w Workflow) OnPullRequest(filters ...PullRequestFilter) Workflow {
+	w.On.PullRequest = pullRequest(filters)
+	return w
+}
+
+func (w Workflow) OnPullRequestTarget(filters ...PullRequestFilter) Workflow {
+	w.On.PullRequestTarget = pullRequest(filters)
+	return w
+}
+
+func pullRequest(filters []PullRequestFilter) *OnPullRequest {
+	on := &OnPullRequest{}
+	for _, f := range filters {
+		f.applyPullRequest(on)
+	}
+
+	return on
+}
+
+func (w Workflow) OnWorkflowRun(workflows []string, filters ...WorkflowRunFilter) Workflow {
+	on := &OnWorkflowRun{Workflows: workflows}
+	for _, f := range filters {
+		f.applyWorkflowRun(on)
+	}
+
+	w.On.Run = on
+	return w
+}
+
+func (w Workflow) OnDispatch(inputs map[string]OnDispatchInput) Workflow {
+	w.On.Dispatch = &OnDispatch{Inputs: inputs}
+	return w
+}
+
+func (w Workflow) OnCall(call OnCall) Workflow {
+	w.On.Call = &call
+	return w
+}
+
+func (w Workflow) WithEnv(key, value string) Workflow {
+	w.Env = maps.Clone(w.Env)
+	if w.Env == nil {
+		w.Env = make(map[string]string)
+	}
+
+	w.Env[key] = value
+	return w
+}
+
+func (w Workflow) WithPermissions(p Permissions) Workflow {
+	w.Permissions = p
+	return w
+}
+
+func (w Workflow) WithConcurrency(c Concurrency) Workflow {
+	w.Concurrency = c
+	return w
+}
+
+func (w Workflow) WithDefaults(d Defaults) Workflow {
+	w.Defaults = d
+	return w
+}
+
+// Job adds or replaces the job with the given ID
+func (w Workflow) Job(id string, job Job) Workflow {
+	w.Jobs = maps.Clone(w.Jobs)
+	if w.Jobs == nil {
+		w.Jobs = make(map[string]Job)
+	}
+
+	w.Jobs[id] = job
+	return w
+}
+
+func NewJob(runsOn ...string) Job {
+	return Job{RunsOn: runsOn}
+}
+
+func (j Job) WithName(name string) Job {
+	j.Name = name
+	return j
+}
+
+func (j Job) WithNeeds(jobIDs ...string) Job {
+	j.Needs = append(slices.Clone(j.Needs), jobIDs...)
+	return j
+}
+
+// WithIf sets the condition, the surrounding ${{ }} is optional for conditions and left out
+func (j Job) WithIf(expr expressions.Expression) Job {
+	j.If = string(expr)
+	return j
+}
+
+func (j Job) WithEnv(key, value string) Job {
+	j.Env = maps.Clone(j.Env)
+	if j.Env == nil {
+		j.Env = make(map[string]string)
+	}
+
+	j.Env[key] = value
+	return j
+}
+
+func (j Job) WithOutput(name string, value expressions.Expression) Job {
+	j.Outputs = maps.Clone(j.Outputs)
+	if j.Outputs == nil {
+		j.Outputs = make(map[string]string)
+	}
+
+	j.Outputs[name] = value.String()
+	return j
+}
+
+// WithSteps appends steps
+func (j Job) WithSteps(steps ...Step) Job {
+	j.Steps = append(slices.Clone(j.Steps), steps...)
+	return j
+}
+
+func (j Job) WithPermissions(p Permissions) Job {
+	j.Permissions = p
+	return j
+}
+
+func (j Job) WithEnvironment(env Environment) Job {
+	j.Environment = env
+	return j
+}
+
+func (j Job) WithConcurrency(c Concurrency) Job {
+	j.Concurrency = c
+	return j
+}
+
+func (j Job) WithStrategy(s Strategy) Job {
+	j.Strategy = s
+	return j
+}
+
+func (j Job) WithDefaults(d Defaults) Job {
+	j.Defaults = d
+	return j
+}
+
+func (j Job) WithTimeout(minutes int) Job {
+	j.TimeoutMinutes = IntValue(minutes)
+	return j
+}
+
+func (j Job) WithContainer(c Container) Job {
+	j.Container = c
+	return j
+}
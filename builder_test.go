@@ -0,0 +1,62 @@
+package gocto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cakehappens/gocto/expressions"
+)
+
+func TestBuilder(t *testing.T) {
+	build := NewJob("ubuntu-latest").
+		WithTimeout(10).
+		WithOutput("version", expressions.StepOutput("version", "value")).
+		WithSteps(
+			Step{Run: "make"}.WithID("version").WithShell(ShellBash).WithWorkingDirectory("src").WithTimeout(5),
+			Step{Uses: "actions/upload-artifact@v4"}.WithWith("name", "dist").WithIf("github.event_name == 'push'"),
+		)
+
+	base := NewWorkflow("CI").
+		OnPush(Branches("main"), Tags("v*"), PathsIgnore("docs/**")).
+		OnPullRequest(Branches("main"), Paths("**.go")).
+		WithEnv("GOFLAGS", "-mod=mod").
+		Job("build", build)
+
+	w := base.Job("deploy", NewJob("ubuntu-latest").WithNeeds("build").WithEnvironment(Environment{Name: "production"}))
+
+	expected := Workflow{
+		Name: "CI",
+		On: WorkflowOn{
+			Push: &OnPush{
+				OnBranches: &OnBranches{Branches: []string{"main"}},
+				OnTags:     &OnTags{Tags: []string{"v*"}},
+				OnPaths:    &OnPaths{PathsIgnore: []string{"docs/**"}},
+			},
+			PullRequest: &OnPullRequest{
+				OnBranches: &OnBranches{Branches: []string{"main"}},
+				OnPaths:    &OnPaths{Paths: []string{"**.go"}},
+			},
+		},
+		Env: map[string]string{"GOFLAGS": "-mod=mod"},
+		Jobs: map[string]Job{
+			"build": {
+				RunsOn:         StringOrSlice{"ubuntu-latest"},
+				TimeoutMinutes: IntValue(10),
+				Outputs:        map[string]string{"version": "${{steps.version.outputs.value}}"},
+				Steps: []Step{
+					{ID: "version", Run: "make", Shell: ShellBash, WorkingDirectory: "src", TimeoutMinutes: IntValue(5)},
+					{Uses: "actions/upload-artifact@v4", With: map[string]any{"name": "dist"}, If: "github.event_name == 'push'"},
+				},
+			},
+			"deploy": {
+				RunsOn:      StringOrSlice{"ubuntu-latest"},
+				Needs:       StringOrSlice{"build"},
+				Environment: Environment{Name: "production"},
+			},
+		},
+	}
+
+	assert.Equal(t, expected, w)
+	assert.Len(t, base.Jobs, 1, "building on a workflow does not modify it")
+}
@@ -0,0 +1,158 @@
+// Package cli implements the gocto command on top of the workflows passed to gocto.Register.
+//
+// The gocto binary builds a small program which imports the package registering
+// the workflows and calls Main, so every repository runs the same commands
+// instead of its own generator script.
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/cakehappens/gocto"
+)
+
+const (
+	// ExitOK means the command succeeded, and check or lint found nothing to report
+	ExitOK = 0
+	// ExitFailure means check found stale files, lint found errors or a command failed
+	ExitFailure = 1
+	// ExitUsage means the command line was invalid
+	ExitUsage = 2
+)
+
+// Main runs the gocto command against gocto.DefaultProject
+func Main(args []string, stdout, stderr io.Writer) int {
+	return Run(gocto.DefaultProject(), args, stdout, stderr)
+}
+
+type command struct {
+	name  string
+	usage string
+	run   func(e *env, args []string) int
+}
+
+var commands = []command{
+	{
+		name:  "generate",
+		usage: "write every workflow and action, removing stale generated files",
+		run:   runGenerate,
+	},
+	{
+		name:  "check",
+		usage: "exit 1 when any generated file is missing or out of date",
+		run:   runCheck,
+	},
+	{
+		name:  "diff",
+		usage: "print the changes generate would make",
+		run:   runDiff,
+	},
+	{
+		name:  "lint",
+		usage: "run the lint rules against every workflow",
+		run:   runLint,
+	},
+	{
+		name:  "graph",
+		usage: "print the job dependency graph of every workflow",
+		run:   runGraph,
+	},
+	{
+		name:  "plan",
+		usage: "print which jobs and steps would run for an event",
+		run:   runPlan,
+	},
+	{
+		name:  "run",
+		usage: "run the steps of a job locally with bash",
+		run:   runRun,
+	},
+}
+
+type env struct {
+	project *gocto.Project
+	stdout  io.Writer
+	stderr  io.Writer
+}
+
+func (e *env) errorf(format string, args ...any) {
+	fmt.Fprintf(e.stderr, "gocto: "+format+"\n", args...)
+}
+
+// Run runs the gocto command against the given project
+func Run(project *gocto.Project, args []string, stdout, stderr io.Writer) int {
+	e := &env{
+		project: project,
+		stdout:  stdout,
+		stderr:  stderr,
+	}
+
+	if len(args) == 0 {
+		e.usage()
+		return ExitUsage
+	}
+
+	for _, c := range commands {
+		if c.name == args[0] {
+			return c.run(e, args[1:])
+		}
+	}
+
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		e.usage()
+		return ExitOK
+	}
+
+	e.errorf("unknown command %q", args[0])
+	e.usage()
+	return ExitUsage
+}
+
+func (e *env) usage() {
+	fmt.Fprintln(e.stderr, "usage: gocto <command> [flags]")
+	fmt.Fprintln(e.stderr)
+	fmt.Fprintln(e.stderr, "commands:")
+	for _, c := range commands {
+		fmt.Fprintf(e.stderr, "  %-10s %s\n", c.name, c.usage)
+	}
+}
+
+func (e *env) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	return fs
+}
+
+// selectWorkflows returns the resolved workflows named by filename, or all of them when no names are given
+func (e *env) selectWorkflows(names []string) ([]gocto.Workflow, error) {
+	workflows, err := e.project.ResolvedWorkflows()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(names) == 0 {
+		return workflows, nil
+	}
+
+	byName := make(map[string]gocto.Workflow)
+	for _, w := range workflows {
+		byName[w.GetFilename()] = w
+	}
+
+	var selected []gocto.Workflow
+	for _, name := range names {
+		w, ok := byName[filepath.Base(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown workflow %q, expected one of %s", name, strings.Join(slices.Sorted(maps.Keys(byName)), ", "))
+		}
+		selected = append(selected, w)
+	}
+
+	return selected, nil
+}
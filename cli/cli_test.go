@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cakehappens/gocto"
+)
+
+func testProject() *gocto.Project {
+	return gocto.NewProject().AddWorkflow(
+		gocto.Workflow{
+			Name: "CI",
+			On: gocto.WorkflowOn{
+				Push: &gocto.OnPush{},
+			},
+			Jobs: map[string]gocto.Job{
+				"build": {
+					RunsOn: gocto.StringOrSlice{"ubuntu-latest"},
+					Steps:  []gocto.Step{{Run: "make"}},
+				},
+				"deploy": {
+					Needs:          gocto.StringOrSlice{"build"},
+					RunsOn:         gocto.StringOrSlice{"ubuntu-latest"},
+					TimeoutMinutes: gocto.IntValue(10),
+					Steps:          []gocto.Step{{Run: "make deploy"}},
+				},
+			},
+		},
+	)
+}
+
+func run(t *testing.T, args ...string) (int, string) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	code := Run(testProject(), args, &stdout, &stderr)
+	t.Log(stderr.String())
+
+	return code, stdout.String()
+}
+
+func TestGenerateCheckDiff(t *testing.T) {
+	root := t.TempDir()
+	filename := filepath.Join(root, ".github", "workflows", "ci.yml")
+
+	code, out := run(t, "check", "-root", root)
+	assert.Equal(t, ExitFailure, code)
+	assert.Contains(t, out, "ci.yml: missing")
+
+	code, _ = run(t, "generate", "-root", root)
+	require.Equal(t, ExitOK, code)
+
+	doc, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Contains(t, string(doc), gocto.GeneratedHeader)
+
+	code, out = run(t, "check", "-root", root)
+	assert.Equal(t, ExitOK, code)
+	assert.Empty(t, out)
+
+	require.NoError(t, os.WriteFile(filename, bytes.Replace(doc, []byte("make deploy"), []byte("make ship"), 1), 0o644))
+
+	code, out = run(t, "check", "-root", root)
+	assert.Equal(t, ExitFailure, code)
+	assert.Contains(t, out, "ci.yml: out of date")
+
+	code, out = run(t, "diff", "-root", root)
+	assert.Equal(t, ExitFailure, code)
+	assert.Contains(t, out, "-      - run: make ship\n+      - run: make deploy\n")
+}
+
+func TestLint(t *testing.T) {
+	code, out := run(t, "lint", "-format", "json")
+	assert.Equal(t, ExitOK, code)
+
+	var diags []gocto.Diagnostic
+	require.NoError(t, json.Unmarshal([]byte(out), &diags))
+	require.Len(t, diags, 1)
+	assert.Equal(t, "timeout-minutes", diags[0].Rule)
+	assert.Equal(t, gocto.SeverityWarning, diags[0].Severity)
+
+	code, out = run(t, "lint", "-format", "sarif", "-disable", "timeout-minutes")
+	assert.Equal(t, ExitOK, code)
+	assert.Contains(t, out, `"results": []`)
+}
+
+func TestGraph(t *testing.T) {
+	code, out := run(t, "graph", "-format", "dot", "ci.yml")
+	assert.Equal(t, ExitOK, code)
+	assert.Contains(t, out, `"build" -> "deploy";`)
+
+	code, out = run(t, "graph", "ci.yml")
+	assert.Equal(t, ExitOK, code)
+	assert.Contains(t, out, "  job0[\"build\"]\n  job1[\"deploy\"]\n  job0 --> job1\n")
+
+	code, _ = run(t, "graph", "nope.yml")
+	assert.Equal(t, ExitUsage, code)
+}
+
+func TestWriteMermaid(t *testing.T) {
+	w := gocto.Workflow{
+		Name: "CI",
+		Jobs: map[string]gocto.Job{
+			"build-and.test": {},
+			"end":            {Needs: gocto.StringOrSlice{"build-and.test", "x-ray"}},
+		},
+	}
+
+	var out bytes.Buffer
+	writeMermaid(&out, w)
+	assert.Equal(t, `---
+title: ci.yml
+---
+flowchart LR
+  job0["build-and.test"]
+  job1["end"]
+  job0 --> job1
+  job2["x-ray"]
+  job2 --> job1
+`, out.String())
+}
+
+func TestPlan(t *testing.T) {
+	code, out := run(t, "plan", "-event", "push", "-ref", "refs/heads/main")
+	require.Equal(t, ExitOK, code)
+	assert.Contains(t, out, "ci.yml: triggered by push refs/heads/main")
+	assert.Contains(t, out, "stage 2\n  run  deploy")
+
+	code, out = run(t, "plan", "-event", "pull_request")
+	require.Equal(t, ExitOK, code)
+	assert.Contains(t, out, "not triggered")
+}
+
+func TestRun(t *testing.T) {
+	code, out := run(t, "run", "-workspace", t.TempDir(), "ci.yml", "build")
+	assert.Equal(t, ExitFailure, code)
+	assert.Contains(t, out, "--- run  1. Run make\n")
+	assert.Contains(t, out, "job build: failure\n")
+
+	code, _ = run(t, "run", "ci.yml")
+	assert.Equal(t, ExitUsage, code)
+}
@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"path/filepath"
+
+	"github.com/cakehappens/gocto"
+	"github.com/cakehappens/gocto/internal/diff"
+)
+
+type generateFlags struct {
+	root     string
+	pin      bool
+	lockfile string
+}
+
+func (e *env) generateFlagSet(name string, f *generateFlags) *flag.FlagSet {
+	fs := e.flagSet(name)
+	fs.StringVar(&f.root, "root", ".", "repository root the workflows and actions directories are relative to")
+	fs.BoolVar(&f.pin, "pin", false, "pin action references to commit SHAs recorded in the lockfile")
+	fs.StringVar(&f.lockfile, "lockfile", gocto.DefaultLockfileName, "lockfile path, relative to -root")
+	return fs
+}
+
+func (f *generateFlags) lockfilePath() string {
+	return filepath.Join(f.root, f.lockfile)
+}
+
+// project returns a copy of the project pinning with the lockfile when -pin is set.
+// Unknown references are only resolved when resolver is set
+func (f *generateFlags) project(p *gocto.Project, resolver gocto.ActionResolver) (*gocto.Project, error) {
+	project := *p
+	if !f.pin {
+		return &project, nil
+	}
+
+	lock, err := gocto.ReadLockfile(f.lockfilePath())
+	if err != nil {
+		return nil, err
+	}
+
+	project.Pinner = &gocto.Pinner{
+		Lockfile: lock,
+		Resolver: resolver,
+	}
+
+	return &project, nil
+}
+
+func runGenerate(e *env, args []string) int {
+	var f generateFlags
+	if err := e.generateFlagSet("generate", &f).Parse(args); err != nil {
+		return ExitUsage
+	}
+
+	project, err := f.project(e.project, gocto.GitResolver{})
+	if err != nil {
+		e.errorf("%v", err)
+		return ExitFailure
+	}
+
+	statuses, err := project.WriteFiles(context.Background(), f.root)
+	if err != nil {
+		e.errorf("%v", err)
+		return ExitFailure
+	}
+
+	for _, s := range statuses {
+		if s.State == gocto.FileStateStale {
+			fmt.Fprintf(e.stdout, "removed %s\n", s.Path)
+		} else {
+			fmt.Fprintf(e.stdout, "wrote %s\n", s.Path)
+		}
+	}
+
+	if project.Pinner != nil {
+		if err := project.Pinner.Lockfile.WriteFile(f.lockfilePath()); err != nil {
+			e.errorf("%v", err)
+			return ExitFailure
+		}
+	}
+
+	return ExitOK
+}
+
+func runCheck(e *env, args []string) int {
+	var f generateFlags
+	if err := e.generateFlagSet("check", &f).Parse(args); err != nil {
+		return ExitUsage
+	}
+
+	project, err := f.project(e.project, nil)
+	if err != nil {
+		e.errorf("%v", err)
+		return ExitFailure
+	}
+
+	statuses, err := project.Check(context.Background(), f.root)
+	if err != nil {
+		e.errorf("%v", err)
+		return ExitFailure
+	}
+
+	for _, s := range statuses {
+		fmt.Fprintf(e.stdout, "%s: %s\n", s.Path, s.State)
+	}
+
+	if len(statuses) > 0 {
+		fmt.Fprintln(e.stdout, "run gocto generate to update the workflows")
+		return ExitFailure
+	}
+
+	return ExitOK
+}
+
+func runDiff(e *env, args []string) int {
+	var f generateFlags
+	if err := e.generateFlagSet("diff", &f).Parse(args); err != nil {
+		return ExitUsage
+	}
+
+	project, err := f.project(e.project, nil)
+	if err != nil {
+		e.errorf("%v", err)
+		return ExitFailure
+	}
+
+	statuses, err := project.Check(context.Background(), f.root)
+	if err != nil {
+		e.errorf("%v", err)
+		return ExitFailure
+	}
+
+	for _, s := range statuses {
+		fmt.Fprint(e.stdout, diff.Unified("a/"+s.Path, "b/"+s.Path, s.Existing, s.Content))
+	}
+
+	if len(statuses) > 0 {
+		return ExitFailure
+	}
+
+	return ExitOK
+}
@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/cakehappens/gocto"
+)
+
+func runGraph(e *env, args []string) int {
+	fs := e.flagSet("graph")
+	format := fs.String("format", "mermaid", "output format: mermaid or dot")
+	if err := fs.Parse(args); err != nil {
+		return ExitUsage
+	}
+
+	workflows, err := e.selectWorkflows(fs.Args())
+	if err != nil {
+		e.errorf("%v", err)
+		return ExitUsage
+	}
+
+	var write func(io.Writer, gocto.Workflow)
+	switch *format {
+	case "mermaid":
+		write = writeMermaid
+	case "dot":
+		write = writeDot
+	default:
+		e.errorf("unknown format %q", *format)
+		return ExitUsage
+	}
+
+	for i, w := range workflows {
+		if i > 0 {
+			fmt.Fprintln(e.stdout)
+		}
+		write(e.stdout, w)
+	}
+
+	return ExitOK
+}
+
+// writeMermaid numbers the nodes and puts the job IDs in their labels,
+// job IDs like end or build.test aren't valid mermaid node IDs
+func writeMermaid(out io.Writer, w gocto.Workflow) {
+	fmt.Fprintf(out, "---\ntitle: %s\n---\n", w.GetFilename())
+	fmt.Fprintln(out, "flowchart LR")
+
+	nodes := make(map[string]string)
+	node := func(jobID string) string {
+		id, ok := nodes[jobID]
+		if !ok {
+			id = fmt.Sprintf("job%d", len(nodes))
+			nodes[jobID] = id
+			fmt.Fprintf(out, "  %s[\"%s\"]\n", id, strings.ReplaceAll(jobID, `"`, "#quot;"))
+		}
+		return id
+	}
+
+	jobIDs := slices.Sorted(maps.Keys(w.Jobs))
+	for _, jobID := range jobIDs {
+		node(jobID)
+	}
+
+	for _, jobID := range jobIDs {
+		for _, need := range w.Jobs[jobID].Needs {
+			fmt.Fprintf(out, "  %s --> %s\n", node(need), nodes[jobID])
+		}
+	}
+}
+
+func writeDot(out io.Writer, w gocto.Workflow) {
+	fmt.Fprintf(out, "digraph %q {\n", w.GetFilename())
+	fmt.Fprintln(out, "  rankdir=LR;")
+	for _, jobID := range slices.Sorted(maps.Keys(w.Jobs)) {
+		fmt.Fprintf(out, "  %q;\n", jobID)
+		for _, need := range w.Jobs[jobID].Needs {
+			fmt.Fprintf(out, "  %q -> %q;\n", need, jobID)
+		}
+	}
+	fmt.Fprintln(out, "}")
+}
@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/cakehappens/gocto"
+)
+
+func runLint(e *env, args []string) int {
+	fs := e.flagSet("lint")
+	format := fs.String("format", "text", "output format: text, json or sarif")
+	disable := fs.String("disable", "", "comma separated rules to disable")
+	if err := fs.Parse(args); err != nil {
+		return ExitUsage
+	}
+
+	workflows, err := e.selectWorkflows(fs.Args())
+	if err != nil {
+		e.errorf("%v", err)
+		return ExitUsage
+	}
+
+	linter := gocto.NewLinter()
+	if *disable != "" {
+		linter.Disable(strings.Split(*disable, ",")...)
+	}
+
+	diags := linter.LintAll(workflows...)
+
+	switch *format {
+	case "text":
+		for _, d := range diags {
+			fmt.Fprintln(e.stdout, d)
+		}
+	case "json":
+		err = writeJSON(e.stdout, diags)
+	case "sarif":
+		err = writeJSON(e.stdout, sarifLog(linter.Rules(), diags))
+	default:
+		e.errorf("unknown format %q", *format)
+		return ExitUsage
+	}
+
+	if err != nil {
+		e.errorf("%v", err)
+		return ExitFailure
+	}
+
+	for _, d := range diags {
+		if d.Severity == gocto.SeverityError {
+			return ExitFailure
+		}
+	}
+
+	return ExitOK
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(v)
+}
+
+// SARIF 2.1.0, only the parts code scanning needs
+// https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+
+type sarif struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+}
+
+func sarifLevel(s gocto.Severity) string {
+	switch s {
+	case gocto.SeverityError:
+		return "error"
+	case gocto.SeverityWarning:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+func sarifLog(rules []gocto.Rule, diags []gocto.Diagnostic) sarif {
+	driver := sarifDriver{
+		Name:           "gocto",
+		InformationURI: "https://github.com/cakehappens/gocto",
+		Rules:          []sarifRule{},
+	}
+
+	for _, r := range rules {
+		driver.Rules = append(driver.Rules, sarifRule{
+			ID:               r.Name,
+			ShortDescription: sarifMessage{Text: r.Description},
+		})
+	}
+
+	results := []sarifResult{}
+	for _, d := range diags {
+		results = append(results, sarifResult{
+			RuleID:  d.Rule,
+			Level:   sarifLevel(d.Severity),
+			Message: sarifMessage{Text: d.Message},
+			Locations: []sarifLocation{
+				{
+					PhysicalLocation: sarifPhysicalLocation{
+						ArtifactLocation: sarifArtifactLocation{
+							URI: path.Join(strings.TrimPrefix(gocto.DefaultPathToWorkflows, "./"), d.Workflow),
+						},
+					},
+					LogicalLocations: []sarifLogicalLocation{
+						{FullyQualifiedName: d.Path},
+					},
+				},
+			},
+		})
+	}
+
+	return sarif{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{
+			{
+				Tool:    sarifTool{Driver: driver},
+				Results: results,
+			},
+		},
+	}
+}
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cakehappens/gocto/plan"
+)
+
+func runPlan(e *env, args []string) int {
+	fs := e.flagSet("plan")
+	event := fs.String("event", "push", "event name, e.g. push, pull_request or workflow_dispatch")
+	ref := fs.String("ref", "refs/heads/main", "ref the workflow runs on")
+	baseRef := fs.String("base-ref", "", "target branch of a pull request")
+	headRef := fs.String("head-ref", "", "source branch of a pull request")
+	paths := fs.String("paths", "", "comma separated changed paths")
+	payload := fs.String("payload", "", "file with the JSON event payload")
+	if err := fs.Parse(args); err != nil {
+		return ExitUsage
+	}
+
+	ev := plan.Event{
+		Name:    *event,
+		Ref:     *ref,
+		BaseRef: *baseRef,
+		HeadRef: *headRef,
+	}
+	if *paths != "" {
+		ev.ChangedPaths = strings.Split(*paths, ",")
+	}
+
+	if *payload != "" {
+		b, err := os.ReadFile(*payload)
+		if err != nil {
+			e.errorf("%v", err)
+			return ExitUsage
+		}
+
+		if err := json.Unmarshal(b, &ev.Payload); err != nil {
+			e.errorf("%s: %v", *payload, err)
+			return ExitUsage
+		}
+	}
+
+	workflows, err := e.selectWorkflows(fs.Args())
+	if err != nil {
+		e.errorf("%v", err)
+		return ExitUsage
+	}
+
+	for i, w := range workflows {
+		p, err := plan.New(w, ev)
+		if err != nil {
+			e.errorf("%s: %v", w.GetFilename(), err)
+			return ExitFailure
+		}
+
+		if i > 0 {
+			fmt.Fprintln(e.stdout)
+		}
+		p.Print(e.stdout)
+	}
+
+	return ExitOK
+}
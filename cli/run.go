@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+
+	"github.com/cakehappens/gocto/expressions"
+	"github.com/cakehappens/gocto/runner"
+)
+
+// keyValues is a repeatable name=value flag, a name without a value is read from the environment
+type keyValues map[string]string
+
+func (kv keyValues) String() string {
+	return fmt.Sprint(map[string]string(kv))
+}
+
+func (kv keyValues) Set(s string) error {
+	name, value, ok := strings.Cut(s, "=")
+	if name == "" {
+		return fmt.Errorf("expected name=value, got %q", s)
+	}
+
+	if !ok {
+		value = os.Getenv(name)
+	}
+
+	kv[name] = value
+	return nil
+}
+
+func runRun(e *env, args []string) int {
+	r := runner.New()
+	r.Stdout = e.stdout
+	r.Stderr = e.stderr
+	r.Matrix = make(keyValues)
+	r.Secrets = make(keyValues)
+	r.Vars = make(keyValues)
+
+	fs := e.flagSet("run")
+	fs.StringVar(&r.Workspace, "workspace", ".", "directory the steps run in")
+	fs.StringVar(&r.Event.Name, "event", r.Event.Name, "event name, e.g. push or workflow_dispatch")
+	fs.StringVar(&r.Event.Ref, "ref", r.Event.Ref, "ref the workflow runs on")
+	fs.StringVar(&r.Event.BaseRef, "base-ref", "", "target branch of a pull request")
+	fs.Var(keyValues(r.Matrix), "matrix", "matrix value selecting the combination to run, name=value, repeatable")
+	fs.Var(keyValues(r.Secrets), "secret", "secret as name=value, or name to read it from the environment, repeatable")
+	fs.Var(keyValues(r.Vars), "var", "configuration variable as name=value, repeatable")
+	if err := fs.Parse(args); err != nil {
+		return ExitUsage
+	}
+
+	if fs.NArg() != 2 {
+		e.errorf("usage: gocto run [flags] <workflow> <job>")
+		return ExitUsage
+	}
+
+	workflows, err := e.selectWorkflows(fs.Args()[:1])
+	if err != nil {
+		e.errorf("%v", err)
+		return ExitUsage
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	res, err := r.RunJob(ctx, workflows[0], fs.Arg(1))
+	if err != nil {
+		e.errorf("%v", err)
+		return ExitFailure
+	}
+
+	fmt.Fprintf(e.stdout, "job %s: %s\n", res.Job, res.Result)
+	for _, name := range slices.Sorted(maps.Keys(res.Outputs)) {
+		fmt.Fprintf(e.stdout, "  output %s=%s\n", name, res.Outputs[name])
+	}
+
+	if res.Result != expressions.StatusSuccess {
+		return ExitFailure
+	}
+
+	return ExitOK
+}
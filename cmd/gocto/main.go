@@ -0,0 +1,132 @@
+// Command gocto generates, checks, diffs, lints and graphs the workflows
+// registered with gocto.Register by a package of the current module.
+//
+//	gocto [-pkg ./ci] <command> [flags]
+//
+// The package is compiled into a temporary program together with the
+// github.com/cakehappens/gocto/cli package, which implements the commands.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/cakehappens/gocto/cli"
+)
+
+var mainTemplate = template.Must(template.New("main").Parse(`// Code generated by gocto; DO NOT EDIT.
+
+package main
+
+import (
+	"os"
+
+	"github.com/cakehappens/gocto/cli"
+
+	_ {{ printf "%q" . }}
+)
+
+func main() {
+	os.Exit(cli.Main(os.Args[1:], os.Stdout, os.Stderr))
+}
+`))
+
+func main() {
+	fs := flag.NewFlagSet("gocto", flag.ContinueOnError)
+	pkg := fs.String("pkg", "./ci", "package which registers the workflows")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: gocto [-pkg ./ci] <command> [flags]")
+		fs.PrintDefaults()
+		cli.Run(nil, []string{"help"}, os.Stdout, os.Stderr)
+	}
+
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(cli.ExitOK)
+		}
+		os.Exit(cli.ExitUsage)
+	}
+
+	code, err := run(*pkg, fs.Args())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gocto: %v\n", err)
+	}
+
+	os.Exit(code)
+}
+
+// run builds a program which imports pkg and runs the cli, then runs it with args
+func run(pkg string, args []string) (int, error) {
+	importPath, err := goOutput("list", "-f", "{{.ImportPath}}", pkg)
+	if err != nil {
+		return cli.ExitUsage, err
+	}
+
+	gomod, err := goOutput("env", "GOMOD")
+	if err != nil {
+		return cli.ExitFailure, err
+	}
+
+	if gomod == "" || gomod == os.DevNull {
+		return cli.ExitUsage, errors.New("gocto must be run inside a Go module")
+	}
+
+	// the program has to live inside the module to import its packages,
+	// the leading _ keeps it out of ./... patterns while it exists
+	dir, err := os.MkdirTemp(filepath.Dir(gomod), "_gocto-")
+	if err != nil {
+		return cli.ExitFailure, err
+	}
+	defer os.RemoveAll(dir)
+
+	var src bytes.Buffer
+	if err := mainTemplate.Execute(&src, importPath); err != nil {
+		return cli.ExitFailure, err
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), src.Bytes(), 0o644); err != nil {
+		return cli.ExitFailure, err
+	}
+
+	bin := filepath.Join(dir, "gocto-main")
+	build := exec.Command("go", "build", "-o", bin, ".")
+	build.Dir = dir
+	build.Stdout = os.Stderr
+	build.Stderr = os.Stderr
+	if err := build.Run(); err != nil {
+		return cli.ExitFailure, fmt.Errorf("building %s: %w", importPath, err)
+	}
+
+	cmd := exec.Command(bin, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	var exitErr *exec.ExitError
+	if err := cmd.Run(); errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), nil
+	} else if err != nil {
+		return cli.ExitFailure, err
+	}
+
+	return cli.ExitOK, nil
+}
+
+func goOutput(args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("go", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("go %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -0,0 +1,137 @@
+// Package commands formats the workflow commands a step writes to its output to talk to the runner,
+// e.g. annotations, masks and log groups.
+//
+// The functions return the command line, escaped as the runner expects. Echo turns it into
+// the line of a bash or sh script which prints it:
+//
+//	run := commands.Echo(commands.Error("build failed", commands.AnnotationProperties{File: "main.go", StartLine: 3}))
+//
+// https://docs.github.com/en/actions/reference/workflow-commands-for-github-actions
+package commands
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// AnnotationProperties are the optional location of an annotation, the lines and columns start at 1
+type AnnotationProperties struct {
+	Title       string
+	File        string
+	StartLine   int
+	EndLine     int
+	StartColumn int
+	EndColumn   int
+}
+
+type property struct {
+	name  string
+	value string
+}
+
+func (p AnnotationProperties) properties() []property {
+	var props []property
+	add := func(name, value string) {
+		if value != "" {
+			props = append(props, property{name, value})
+		}
+	}
+	number := func(name string, value int) {
+		if value != 0 {
+			add(name, strconv.Itoa(value))
+		}
+	}
+
+	add("title", p.Title)
+	add("file", p.File)
+	number("line", p.StartLine)
+	number("endLine", p.EndLine)
+	number("col", p.StartColumn)
+	number("endColumn", p.EndColumn)
+	return props
+}
+
+// Command formats any workflow command, e.g. Command("add-matcher", nil, ".github/matcher.json")
+func Command(name string, properties map[string]string, message string) string {
+	var props []property
+	for _, k := range slices.Sorted(maps.Keys(properties)) {
+		props = append(props, property{k, properties[k]})
+	}
+
+	return command(name, props, message)
+}
+
+func command(name string, props []property, message string) string {
+	var b strings.Builder
+	b.WriteString("::" + name)
+	for i, p := range props {
+		if i == 0 {
+			b.WriteString(" ")
+		} else {
+			b.WriteString(",")
+		}
+		b.WriteString(p.name + "=" + EscapeProperty(p.value))
+	}
+
+	b.WriteString("::" + EscapeData(message))
+	return b.String()
+}
+
+func Error(message string, props AnnotationProperties) string {
+	return command("error", props.properties(), message)
+}
+
+func Warning(message string, props AnnotationProperties) string {
+	return command("warning", props.properties(), message)
+}
+
+func Notice(message string, props AnnotationProperties) string {
+	return command("notice", props.properties(), message)
+}
+
+// Debug is only shown when debug logging is enabled
+func Debug(message string) string {
+	return command("debug", nil, message)
+}
+
+// AddMask replaces value with *** in the rest of the log
+func AddMask(value string) string {
+	return command("add-mask", nil, value)
+}
+
+// Group starts a group folding the log until EndGroup
+func Group(title string) string {
+	return command("group", nil, title)
+}
+
+func EndGroup() string {
+	return command("endgroup", nil, "")
+}
+
+// StopCommands stops processing workflow commands, until ResumeCommands is given the same token.
+// Use a token which doesn't appear in the output, e.g. a random one.
+func StopCommands(token string) string {
+	return command("stop-commands", nil, token)
+}
+
+func ResumeCommands(token string) string {
+	return command(token, nil, "")
+}
+
+// EscapeData escapes the message of a command
+func EscapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// EscapeProperty escapes the value of a property of a command, which can't contain : and , either
+func EscapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+// Echo is the bash or sh line printing the command.
+// The command is quoted for the shell, but ${{ }} in it are still evaluated by GitHub before the script runs.
+func Echo(command string) string {
+	return "echo '" + strings.ReplaceAll(command, "'", `'\''`) + "'"
+}
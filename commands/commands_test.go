@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommands(t *testing.T) {
+	type testCase struct {
+		name     string
+		command  string
+		expected string
+	}
+
+	testCases := []testCase{
+		{name: "error", command: Error("build failed", AnnotationProperties{}), expected: "::error::build failed"},
+		{
+			name: "warning with location",
+			command: Warning("deprecated\nuse v2", AnnotationProperties{
+				Title:       "Deprecated: v1, v2",
+				File:        "cmd/main.go",
+				StartLine:   3,
+				EndLine:     4,
+				StartColumn: 1,
+			}),
+			expected: "::warning title=Deprecated%3A v1%2C v2,file=cmd/main.go,line=3,endLine=4,col=1::deprecated%0Ause v2",
+		},
+		{name: "notice", command: Notice("100% done\r\n", AnnotationProperties{File: "a.go"}), expected: "::notice file=a.go::100%25 done%0D%0A"},
+		{name: "debug", command: Debug("a: b, c"), expected: "::debug::a: b, c"},
+		{name: "mask", command: AddMask("s3cr3t"), expected: "::add-mask::s3cr3t"},
+		{name: "group", command: Group("Build: linux"), expected: "::group::Build: linux"},
+		{name: "endgroup", command: EndGroup(), expected: "::endgroup::"},
+		{name: "stop", command: StopCommands("t0k3n"), expected: "::stop-commands::t0k3n"},
+		{name: "resume", command: ResumeCommands("t0k3n"), expected: "::t0k3n::"},
+		{
+			name:     "any",
+			command:  Command("add-matcher", map[string]string{"owner": "go", "b": "x,y"}, ".github/go.json"),
+			expected: "::add-matcher b=x%2Cy,owner=go::.github/go.json",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.command)
+		})
+	}
+}
+
+func TestEcho(t *testing.T) {
+	line := Echo(Error("it's $HOME `date`", AnnotationProperties{Title: "a'b"}))
+	assert.Equal(t, `echo '::error title=a'\''b::it'\''s $HOME `+"`date`"+`'`, line)
+
+	out, err := exec.Command("bash", "-c", line).CombinedOutput()
+	require.NoError(t, err, string(out))
+	assert.Equal(t, "::error title=a'b::it's $HOME `date`\n", string(out))
+}
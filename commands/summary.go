@@ -0,0 +1,149 @@
+package commands
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Summary is the Markdown a step adds to the summary of the run, by appending it to $GITHUB_STEP_SUMMARY.
+// Like the builders of gocto, its methods return a modified copy.
+//
+//	summary := commands.Summary{}.
+//		Heading(2, "Tests").
+//		Table([]string{"Package", "Result"}, []string{"./cli", "ok"}).
+//		Details("Log", commands.Summary{}.CodeBlock("text", log))
+type Summary struct {
+	blocks []string
+}
+
+func (s Summary) add(block string) Summary {
+	s.blocks = append(slices.Clone(s.blocks), block)
+	return s
+}
+
+// Heading adds a heading of level 1 to 6
+func (s Summary) Heading(level int, text string) Summary {
+	level = min(max(level, 1), 6)
+	return s.add(strings.Repeat("#", level) + " " + text)
+}
+
+// Paragraph adds text, which can contain inline Markdown like Link and Code
+func (s Summary) Paragraph(text string) Summary {
+	return s.add(text)
+}
+
+// Raw adds Markdown or HTML as is
+func (s Summary) Raw(markdown string) Summary {
+	return s.add(strings.TrimSuffix(markdown, "\n"))
+}
+
+// CodeBlock adds a fenced block, the fence is longer than any run of backticks in code
+func (s Summary) CodeBlock(lang, code string) Summary {
+	fence := "```"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+
+	return s.add(fence + lang + "\n" + strings.TrimSuffix(code, "\n") + "\n" + fence)
+}
+
+func (s Summary) List(items ...string) Summary {
+	lines := make([]string, 0, len(items))
+	for _, item := range items {
+		lines = append(lines, "- "+item)
+	}
+
+	return s.add(strings.Join(lines, "\n"))
+}
+
+func (s Summary) OrderedList(items ...string) Summary {
+	lines := make([]string, 0, len(items))
+	for i, item := range items {
+		lines = append(lines, fmt.Sprintf("%d. %s", i+1, item))
+	}
+
+	return s.add(strings.Join(lines, "\n"))
+}
+
+func (s Summary) Quote(text string) Summary {
+	return s.add("> " + strings.ReplaceAll(strings.TrimSuffix(text, "\n"), "\n", "\n> "))
+}
+
+func (s Summary) Separator() Summary {
+	return s.add("---")
+}
+
+// Table adds a table, rows shorter than the header are padded with empty cells
+func (s Summary) Table(header []string, rows ...[]string) Summary {
+	lines := []string{
+		tableRow(header, len(header)),
+		tableRow(slices.Repeat([]string{"---"}, len(header)), len(header)),
+	}
+	for _, row := range rows {
+		lines = append(lines, tableRow(row, len(header)))
+	}
+
+	return s.add(strings.Join(lines, "\n"))
+}
+
+func tableRow(cells []string, columns int) string {
+	escaped := make([]string, columns)
+	for i := range min(len(cells), columns) {
+		escaped[i] = tableCell.Replace(cells[i])
+	}
+
+	return "| " + strings.Join(escaped, " | ") + " |"
+}
+
+var tableCell = strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>")
+
+// Details adds a collapsible section, which is closed until its summary is clicked
+func (s Summary) Details(summary string, body Summary) Summary {
+	return s.add("<details><summary>" + summary + "</summary>\n\n" + strings.Join(body.blocks, "\n\n") + "\n\n</details>")
+}
+
+// String renders the Markdown, blocks are separated by empty lines
+func (s Summary) String() string {
+	if len(s.blocks) == 0 {
+		return ""
+	}
+
+	return strings.Join(s.blocks, "\n\n") + "\n"
+}
+
+// Append is the bash or sh script appending the summary to $GITHUB_STEP_SUMMARY.
+// Nothing in the Markdown is expanded by the shell, but ${{ }} are still evaluated by GitHub.
+func (s Summary) Append() string {
+	content := strings.TrimSuffix(s.String(), "\n")
+	delimiter := "EOF"
+	for slices.Contains(strings.Split(content, "\n"), delimiter) {
+		delimiter += "_"
+	}
+
+	return `cat >> "$GITHUB_STEP_SUMMARY" <<'` + delimiter + "'\n" + content + "\n" + delimiter
+}
+
+// Link is an inline link, to use in the text of the summary
+func Link(text, url string) string {
+	return "[" + text + "](" + url + ")"
+}
+
+var backticks = regexp.MustCompile("`+")
+
+// Code is inline code, to use in the text of the summary
+func Code(text string) string {
+	fence := "`"
+	for _, run := range backticks.FindAllString(text, -1) {
+		if len(run) >= len(fence) {
+			fence = strings.Repeat("`", len(run)+1)
+		}
+	}
+
+	if strings.HasPrefix(text, "`") || strings.HasSuffix(text, "`") {
+		return fence + " " + text + " " + fence
+	}
+
+	return fence + text + fence
+}
@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummary(t *testing.T) {
+	summary := Summary{}.
+		Heading(2, "Tests").
+		Paragraph("Ran on "+Code("ubuntu-latest")+", see "+Link("the docs", "https://example.com")+".").
+		Table([]string{"Package", "Result"}, []string{"./cli", "ok"}, []string{"./a|b", "FAIL\nexit 1"}, []string{"./short"}).
+		List("one", "two").
+		OrderedList("first", "second").
+		Quote("note\nsecond line").
+		Separator().
+		Details("Log", Summary{}.CodeBlock("text", "```\nEOF\n")).
+		Raw("<b>done</b>\n")
+
+	expected := "## Tests\n\n" +
+		"Ran on `ubuntu-latest`, see [the docs](https://example.com).\n\n" +
+		"| Package | Result |\n" +
+		"| --- | --- |\n" +
+		"| ./cli | ok |\n" +
+		"| ./a\\|b | FAIL<br>exit 1 |\n" +
+		"| ./short |  |\n\n" +
+		"- one\n- two\n\n" +
+		"1. first\n2. second\n\n" +
+		"> note\n> second line\n\n" +
+		"---\n\n" +
+		"<details><summary>Log</summary>\n\n" +
+		"````text\n```\nEOF\n````\n\n" +
+		"</details>\n\n" +
+		"<b>done</b>\n"
+
+	assert.Equal(t, expected, summary.String())
+	assert.Equal(t, "", Summary{}.String())
+	assert.Equal(t, "`` `a` ``", Code("`a`"))
+
+	file := filepath.Join(t.TempDir(), "summary.md")
+	cmd := exec.Command("bash", "-c", summary.Append()+"\n"+summary.Append())
+	cmd.Env = []string{"GITHUB_STEP_SUMMARY=" + file, "PATH=" + os.Getenv("PATH")}
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	written, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, expected+expected, string(written))
+}
@@ -0,0 +1,86 @@
+package gocto
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/cakehappens/gocto/expressions"
+	"github.com/cakehappens/gocto/internal/util"
+)
+
+// Concurrency
+// https://docs.github.com/en/actions/reference/workflow-syntax-for-github-actions#concurrency
+type Concurrency struct {
+	Group            string
+	CancelInProgress BoolOrExpression
+	// Shared marks a group deliberately shared with other workflows, e.g. deployments queuing behind each other,
+	// it is not rendered and only silences the concurrency-group-collision rule
+	Shared bool
+}
+
+// ConcurrencyPerRef runs one workflow per ref at a time. Runs on the default branch queue,
+// anywhere else a new run cancels the one in progress.
+// An empty workflow groups by the github.workflow context instead of a fixed name.
+func ConcurrencyPerRef(workflow string) Concurrency {
+	if workflow == "" {
+		workflow = expressions.Expression("github.workflow").String()
+	}
+
+	return Concurrency{
+		Group:            workflow + "-" + expressions.Expression("github.ref").String(),
+		CancelInProgress: BoolExpression("github.ref_name != github.event.repository.default_branch"),
+	}
+}
+
+// ConcurrencyPerPR cancels the run in progress of the workflow when a pull request is updated.
+// Events without a head ref, e.g. pushes, get a group of their own and are never cancelled.
+func ConcurrencyPerPR() Concurrency {
+	return Concurrency{
+		Group:            expressions.Expression("github.workflow").String() + "-" + expressions.Expression("github.head_ref || github.run_id").String(),
+		CancelInProgress: BoolValue(true),
+	}
+}
+
+func (c *Concurrency) UnmarshalJSON(data []byte) error {
+	if util.IsJSONNull(data) {
+		return nil
+	}
+
+	var group string
+	if err := json.Unmarshal(data, &group); err == nil {
+		*c = Concurrency{Group: group}
+		return nil
+	}
+
+	var obj struct {
+		Group            string           `json:"group"`
+		CancelInProgress BoolOrExpression `json:"cancel-in-progress"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+
+	*c = Concurrency{Group: obj.Group, CancelInProgress: obj.CancelInProgress}
+	return nil
+}
+
+// MarshalJSON uses the group shorthand when cancel-in-progress is unset
+func (c Concurrency) MarshalJSON() ([]byte, error) {
+	if c.CancelInProgress.IsZero() {
+		return json.Marshal(c.Group)
+	}
+
+	return json.Marshal(map[string]any{
+		"group":              c.Group,
+		"cancel-in-progress": c.CancelInProgress,
+	})
+}
+
+var workflowContextPattern = regexp.MustCompile(`\$\{\{\s*github\.workflow\s*\}\}`)
+
+// concurrencyKey is the group as it is evaluated for w, github.workflow is different for every workflow
+// and group names are not case sensitive
+func concurrencyKey(w Workflow, group string) string {
+	return strings.ToLower(workflowContextPattern.ReplaceAllLiteralString(group, w.Name))
+}
@@ -0,0 +1,87 @@
+package gocto
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcurrencyJSON(t *testing.T) {
+	type testCase struct {
+		name        string
+		concurrency Concurrency
+		expected    string
+	}
+
+	testCases := []testCase{
+		{
+			name:        "shorthand",
+			concurrency: Concurrency{Group: "deploy"},
+			expected:    `"deploy"`,
+		},
+		{
+			name:        "cancel in progress",
+			concurrency: Concurrency{Group: "deploy", CancelInProgress: BoolValue(true)},
+			expected:    `{"cancel-in-progress":true,"group":"deploy"}`,
+		},
+		{
+			name:        "explicitly not cancelled",
+			concurrency: Concurrency{Group: "deploy", CancelInProgress: BoolValue(false)},
+			expected:    `{"cancel-in-progress":false,"group":"deploy"}`,
+		},
+		{
+			name:        "per ref",
+			concurrency: ConcurrencyPerRef("CI"),
+			expected:    `{"cancel-in-progress":"${{github.ref_name != github.event.repository.default_branch}}","group":"CI-${{github.ref}}"}`,
+		},
+		{
+			name:        "per pull request",
+			concurrency: ConcurrencyPerPR(),
+			expected:    `{"cancel-in-progress":true,"group":"${{github.workflow}}-${{github.head_ref || github.run_id}}"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := json.Marshal(tc.concurrency)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(b))
+
+			var c Concurrency
+			require.NoError(t, json.Unmarshal(b, &c))
+			assert.Equal(t, tc.concurrency, c)
+		})
+	}
+}
+
+func TestLintConcurrencyGroupCollision(t *testing.T) {
+	workflow := func(name string, c Concurrency) Workflow {
+		return Workflow{
+			Name:        name,
+			Concurrency: c,
+			Jobs:        map[string]Job{},
+		}
+	}
+
+	diags := NewLinter().LintAll(
+		workflow("CI", ConcurrencyPerPR()),
+		workflow("Lint", ConcurrencyPerPR()),
+		workflow("Deploy", Concurrency{Group: "deploy-${{ github.ref }}"}),
+		workflow("Rollback", Concurrency{Group: "Deploy-${{ github.ref }}"}),
+		workflow("Release", Concurrency{Group: "deploy-${{ github.ref }}", Shared: true}),
+	)
+
+	var got []string
+	for _, d := range diags {
+		if d.Rule == "concurrency-group-collision" {
+			got = append(got, d.Workflow+": "+d.Message)
+		}
+	}
+
+	assert.Equal(t, []string{
+		`deploy.yml: concurrency group "deploy-${{ github.ref }}" is also used by rollback.yml`,
+		`rollback.yml: concurrency group "Deploy-${{ github.ref }}" is also used by deploy.yml`,
+	}, got)
+}
@@ -0,0 +1,224 @@
+package gocto
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// EnvLevel is where an environment variable is defined
+type EnvLevel string
+
+const (
+	// EnvLevelContainer is Job.Container.Env, the environment the container starts with.
+	// The env of the workflow, job and step is passed to every step run in the container, so it takes precedence.
+	EnvLevelContainer EnvLevel = "container"
+	EnvLevelWorkflow  EnvLevel = "workflow"
+	EnvLevelJob       EnvLevel = "job"
+	EnvLevelStep      EnvLevel = "step"
+)
+
+// EnvVar is a variable of an effective environment, and where it comes from
+type EnvVar struct {
+	Name  string
+	Value string
+	Level EnvLevel
+	// Path is the field defining the variable, e.g. Jobs["build"].Env["GOFLAGS"]
+	Path string
+	// Shadows are the definitions at enclosing levels this one overrides, outermost first
+	Shadows []EnvVar
+}
+
+type envLayer struct {
+	level EnvLevel
+	path  string
+	env   map[string]string
+}
+
+// envLayers returns the env definitions of a step in order of precedence, a step below 0 stops at the job
+func (w Workflow) envLayers(jobID string, step int) []envLayer {
+	job := w.Jobs[jobID]
+	layers := []envLayer{
+		{EnvLevelContainer, jobPath(jobID) + ".Container.", job.Container.Env},
+		{EnvLevelWorkflow, "", w.Env},
+		{EnvLevelJob, jobPath(jobID) + ".", job.Env},
+	}
+
+	if step >= 0 {
+		layers = append(layers, envLayer{EnvLevelStep, stepPath(jobID, step) + ".", job.Steps[step].Env})
+	}
+
+	return layers
+}
+
+// ResolveEnv returns the effective environment of the step at index step of a job, keyed by name.
+// A step below 0 resolves the environment shared by every step of the job.
+func (w Workflow) ResolveEnv(jobID string, step int) (map[string]EnvVar, error) {
+	job, ok := w.Jobs[jobID]
+	if !ok {
+		return nil, fmt.Errorf("unknown job %q", jobID)
+	}
+
+	if step >= len(job.Steps) {
+		return nil, fmt.Errorf("job %q has %d steps, there is no Steps[%d]", jobID, len(job.Steps), step)
+	}
+
+	resolved := make(map[string]EnvVar)
+	for _, layer := range w.envLayers(jobID, step) {
+		for _, name := range slices.Sorted(maps.Keys(layer.env)) {
+			v := EnvVar{
+				Name:  name,
+				Value: layer.env[name],
+				Level: layer.level,
+				Path:  fmt.Sprintf("%sEnv[%q]", layer.path, name),
+			}
+
+			if prev, ok := resolved[name]; ok {
+				v.Shadows = append(slices.Clone(prev.Shadows), EnvVar{
+					Name:  prev.Name,
+					Value: prev.Value,
+					Level: prev.Level,
+					Path:  prev.Path,
+				})
+			}
+
+			resolved[name] = v
+		}
+	}
+
+	return resolved, nil
+}
+
+var (
+	envContextPattern      = regexp.MustCompile(`(?:^|[^\w.-])env\.([A-Za-z_][\w-]*)`)
+	envContextIndexPattern = regexp.MustCompile(`(?:^|[^\w.-])env\[\s*'([^']+)'\s*\]`)
+	// githubEnvPattern finds the variables a script sets with NAME=value or NAME<<DELIMITER on a line writing to GITHUB_ENV
+	githubEnvPattern = regexp.MustCompile(`(?:^|[\s"'])([A-Za-z_]\w*)(?:=|<<)`)
+)
+
+// envReferences returns the names used with the env context in expressions
+func envReferences(expr string) []string {
+	var names []string
+	for _, pattern := range []*regexp.Regexp{envContextPattern, envContextIndexPattern} {
+		for _, m := range pattern.FindAllStringSubmatch(expr, -1) {
+			names = append(names, m[1])
+		}
+	}
+
+	return names
+}
+
+// githubEnvNames returns the variables a run script adds to GITHUB_ENV, as far as can be told without running it
+func githubEnvNames(run string) []string {
+	var names []string
+	for line := range strings.Lines(run) {
+		if !strings.Contains(line, "GITHUB_ENV") {
+			continue
+		}
+
+		for _, m := range githubEnvPattern.FindAllStringSubmatch(line, -1) {
+			names = append(names, m[1])
+		}
+	}
+
+	return names
+}
+
+func checkEnvShadowing(w Workflow) []Diagnostic {
+	var diags []Diagnostic
+	report := func(env map[string]EnvVar, levels ...EnvLevel) {
+		for _, name := range slices.Sorted(maps.Keys(env)) {
+			v := env[name]
+			if len(v.Shadows) == 0 || !slices.Contains(levels, v.Level) {
+				continue
+			}
+
+			shadowed := v.Shadows[len(v.Shadows)-1]
+			diags = append(diags, Diagnostic{
+				Path:    v.Path,
+				Message: fmt.Sprintf("%s overrides %s at the %s level", name, shadowed.Path, shadowed.Level),
+			})
+		}
+	}
+
+	for _, jobID := range sortedJobIDs(w) {
+		jobEnv, _ := w.ResolveEnv(jobID, -1)
+		report(jobEnv, EnvLevelWorkflow, EnvLevelJob)
+
+		for i := range w.Jobs[jobID].Steps {
+			stepEnv, _ := w.ResolveEnv(jobID, i)
+			report(stepEnv, EnvLevelStep)
+		}
+	}
+
+	return diags
+}
+
+// checkUndefinedEnvReference checks the fields which can use the env context.
+// The env context only holds the env of the workflow, job and step and what earlier steps added to GITHUB_ENV,
+// not the env of the container nor the default variables like GITHUB_SHA.
+func checkUndefinedEnvReference(w Workflow) []Diagnostic {
+	var diags []Diagnostic
+	for _, jobID := range sortedJobIDs(w) {
+		job := w.Jobs[jobID]
+
+		defined := make(map[string]bool)
+		define := func(env map[string]string) {
+			for name := range env {
+				defined[strings.ToLower(name)] = true
+			}
+		}
+		define(w.Env)
+		define(job.Env)
+
+		check := func(path string, exprs []string, defined map[string]bool) {
+			for _, expr := range exprs {
+				for _, name := range envReferences(expr) {
+					if defined[strings.ToLower(name)] {
+						continue
+					}
+
+					msg := fmt.Sprintf("env.%s is not defined by the workflow, job or step env", name)
+					if _, ok := job.Container.Env[name]; ok {
+						msg += ", container env is not part of the env context"
+					}
+
+					diags = append(diags, Diagnostic{Path: path, Message: msg})
+				}
+			}
+		}
+
+		for i, s := range job.Steps {
+			// env values are evaluated before the step env is set
+			for _, k := range slices.Sorted(maps.Keys(s.Env)) {
+				check(fmt.Sprintf("%s.Env[%q]", stepPath(jobID, i), k), expressionsIn(s.Env[k]), defined)
+			}
+
+			stepDefined := maps.Clone(defined)
+			for name := range s.Env {
+				stepDefined[strings.ToLower(name)] = true
+			}
+
+			check(stepPath(jobID, i)+".If", []string{s.If}, stepDefined)
+
+			fields := stepStrings(s)
+			for _, field := range slices.Sorted(maps.Keys(fields)) {
+				if !strings.HasPrefix(field, "Env[") {
+					check(stepPath(jobID, i)+"."+field, expressionsIn(fields[field]), stepDefined)
+				}
+			}
+
+			for _, name := range githubEnvNames(s.Run) {
+				defined[strings.ToLower(name)] = true
+			}
+		}
+
+		for _, k := range slices.Sorted(maps.Keys(job.Outputs)) {
+			check(fmt.Sprintf("%s.Outputs[%q]", jobPath(jobID), k), expressionsIn(job.Outputs[k]), defined)
+		}
+	}
+
+	return diags
+}
@@ -0,0 +1,222 @@
+package gocto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveEnv(t *testing.T) {
+	type testCase struct {
+		name     string
+		wf       Workflow
+		step     int
+		expected map[string]EnvVar
+		err      string
+	}
+
+	cases := []testCase{
+		{
+			name: "step shadows job and workflow",
+			wf: Workflow{
+				Name: "env",
+				Env:  map[string]string{"GOFLAGS": "-mod=mod"},
+				Jobs: map[string]Job{
+					"build": {
+						Env:   map[string]string{"GOFLAGS": "-mod=vendor"},
+						Steps: []Step{{Env: map[string]string{"GOFLAGS": "-race"}, Run: "go test ./..."}},
+					},
+				},
+			},
+			step: 0,
+			expected: map[string]EnvVar{
+				"GOFLAGS": {
+					Name:  "GOFLAGS",
+					Value: "-race",
+					Level: EnvLevelStep,
+					Path:  `Jobs["build"].Steps[0].Env["GOFLAGS"]`,
+					Shadows: []EnvVar{
+						{Name: "GOFLAGS", Value: "-mod=mod", Level: EnvLevelWorkflow, Path: `Env["GOFLAGS"]`},
+						{Name: "GOFLAGS", Value: "-mod=vendor", Level: EnvLevelJob, Path: `Jobs["build"].Env["GOFLAGS"]`},
+					},
+				},
+			},
+		},
+		{
+			name: "workflow shadows container",
+			wf: Workflow{
+				Name: "env",
+				Env:  map[string]string{"REGION": "us-east-1"},
+				Jobs: map[string]Job{
+					"build": {
+						Container: Container{
+							Image: "golang",
+							Env:   map[string]string{"CGO_ENABLED": "0", "REGION": "eu-west-1"},
+						},
+						Steps: []Step{{Run: "go test ./..."}},
+					},
+				},
+			},
+			step: 0,
+			expected: map[string]EnvVar{
+				"REGION": {
+					Name:    "REGION",
+					Value:   "us-east-1",
+					Level:   EnvLevelWorkflow,
+					Path:    `Env["REGION"]`,
+					Shadows: []EnvVar{{Name: "REGION", Value: "eu-west-1", Level: EnvLevelContainer, Path: `Jobs["build"].Container.Env["REGION"]`}},
+				},
+				"CGO_ENABLED": {
+					Name:  "CGO_ENABLED",
+					Value: "0",
+					Level: EnvLevelContainer,
+					Path:  `Jobs["build"].Container.Env["CGO_ENABLED"]`,
+				},
+			},
+		},
+		{
+			name: "job level",
+			wf: Workflow{
+				Name: "env",
+				Jobs: map[string]Job{
+					"build": {
+						Env:   map[string]string{"GOFLAGS": "-mod=vendor"},
+						Steps: []Step{{Env: map[string]string{"SELF": "${{ env.SELF }}"}, Run: "go test ./..."}},
+					},
+				},
+			},
+			step: -1,
+			expected: map[string]EnvVar{
+				"GOFLAGS": {Name: "GOFLAGS", Value: "-mod=vendor", Level: EnvLevelJob, Path: `Jobs["build"].Env["GOFLAGS"]`},
+			},
+		},
+		{
+			name: "no such step",
+			wf: Workflow{
+				Name: "env",
+				Jobs: map[string]Job{
+					"build": {Steps: []Step{{Run: "make"}}},
+				},
+			},
+			step: 1,
+			err:  `job "build" has 1 steps, there is no Steps[1]`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			env, err := tc.wf.ResolveEnv("build", tc.step)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, env)
+		})
+	}
+}
+
+func TestLintEnv(t *testing.T) {
+	type testCase struct {
+		name     string
+		wf       Workflow
+		expected []Diagnostic
+	}
+
+	cases := []testCase{
+		{
+			name: "shadowing",
+			wf: Workflow{
+				Name: "env",
+				Env:  map[string]string{"GOFLAGS": "-mod=mod", "REGION": "us-east-1"},
+				Jobs: map[string]Job{
+					"build": {
+						Env:       map[string]string{"GOFLAGS": "-mod=vendor"},
+						Container: Container{Image: "golang", Env: map[string]string{"REGION": "eu-west-1"}},
+						Steps:     []Step{{Env: map[string]string{"GOFLAGS": "-race"}, Run: "go test ./..."}},
+					},
+				},
+			},
+			expected: []Diagnostic{
+				{
+					Rule:    "env-shadowing",
+					Path:    `Env["REGION"]`,
+					Message: `REGION overrides Jobs["build"].Container.Env["REGION"] at the container level`,
+				},
+				{
+					Rule:    "env-shadowing",
+					Path:    `Jobs["build"].Env["GOFLAGS"]`,
+					Message: `GOFLAGS overrides Env["GOFLAGS"] at the workflow level`,
+				},
+				{
+					Rule:    "env-shadowing",
+					Path:    `Jobs["build"].Steps[0].Env["GOFLAGS"]`,
+					Message: `GOFLAGS overrides Jobs["build"].Env["GOFLAGS"] at the job level`,
+				},
+			},
+		},
+		{
+			name: "undefined references",
+			wf: Workflow{
+				Name: "env",
+				Jobs: map[string]Job{
+					"build": {
+						Container: Container{Image: "golang", Env: map[string]string{"CGO_ENABLED": "0"}},
+						Steps: []Step{
+							{Env: map[string]string{"SELF": "${{ env.SELF }}"}, Run: "go test ./... ${{ env.CGO_ENABLED }}"},
+							{If: "env.GITHUB_SHA", Run: "make"},
+						},
+					},
+				},
+			},
+			expected: []Diagnostic{
+				{
+					Rule:    "undefined-env-reference",
+					Path:    `Jobs["build"].Steps[0].Env["SELF"]`,
+					Message: "env.SELF is not defined by the workflow, job or step env",
+				},
+				{
+					Rule:    "undefined-env-reference",
+					Path:    `Jobs["build"].Steps[0].Run`,
+					Message: "env.CGO_ENABLED is not defined by the workflow, job or step env, container env is not part of the env context",
+				},
+				{
+					Rule:    "undefined-env-reference",
+					Path:    `Jobs["build"].Steps[1].If`,
+					Message: "env.GITHUB_SHA is not defined by the workflow, job or step env",
+				},
+			},
+		},
+		{
+			name: "defined by an earlier step and case insensitive",
+			wf: Workflow{
+				Name: "env",
+				Env:  map[string]string{"REGION": "us-east-1"},
+				Jobs: map[string]Job{
+					"build": {
+						Outputs: map[string]string{"version": "${{ env.VERSION }}"},
+						Steps: []Step{
+							{Run: `echo "VERSION=1.2.3" >> "$GITHUB_ENV"`},
+							{If: "env.VERSION != ''", Run: "echo ${{ env.version }} ${{ env['REGION'] }}"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []Diagnostic
+			for _, d := range Lint(tc.wf) {
+				if d.Rule == "env-shadowing" || d.Rule == "undefined-env-reference" {
+					got = append(got, Diagnostic{Rule: d.Rule, Path: d.Path, Message: d.Message})
+				}
+			}
+
+			assert.ElementsMatch(t, tc.expected, got)
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package gocto
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/cakehappens/gocto/internal/glob"
+)
+
+// ProductionEnvironment only accepts deployments from protected refs, whether or not its config says so
+const ProductionEnvironment = "production"
+
+// EnvironmentsManifest describes the deployment environments configured in the repository settings,
+// so jobs can be checked against them
+// https://docs.github.com/en/actions/how-tos/deploy/configure-and-manage-deployments/manage-environments
+type EnvironmentsManifest struct {
+	// ProtectedRefs are patterns of the branches and tags with protection rules, e.g. refs/heads/main or refs/tags/v*
+	ProtectedRefs []string
+	Environments  map[string]EnvironmentConfig
+}
+
+// EnvironmentConfig is the deployment protection of a single environment
+type EnvironmentConfig struct {
+	// Reviewers are the users or teams, up to 6, who have to approve a deployment
+	Reviewers         []string
+	PreventSelfReview bool
+	// WaitTimer delays deployments by up to 43200 minutes, 30 days
+	WaitTimer int
+	// ProtectedBranchesOnly only lets ProtectedRefs deploy
+	ProtectedBranchesOnly bool
+	// BranchPolicies are patterns of the refs which can deploy, e.g. refs/heads/release/*, when not only protected refs can
+	BranchPolicies []string
+}
+
+const (
+	maxEnvironmentReviewers = 6
+	maxEnvironmentWaitTimer = 43200
+)
+
+// allowedRefs returns the ref patterns which can deploy to the environment, nil allows every ref
+func (m *EnvironmentsManifest) allowedRefs(name string) []string {
+	cfg := m.Environments[name]
+	if cfg.ProtectedBranchesOnly || name == ProductionEnvironment {
+		return m.ProtectedRefs
+	}
+
+	return cfg.BranchPolicies
+}
+
+func (m *EnvironmentsManifest) validate() []error {
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(m.Environments)) {
+		cfg := m.Environments[name]
+		if len(cfg.Reviewers) > maxEnvironmentReviewers {
+			errs = append(errs, fmt.Errorf("environment %q: %d reviewers, at most %d are allowed", name, len(cfg.Reviewers), maxEnvironmentReviewers))
+		}
+
+		if cfg.WaitTimer < 0 || cfg.WaitTimer > maxEnvironmentWaitTimer {
+			errs = append(errs, fmt.Errorf("environment %q: wait timer of %d minutes, expected 0 to %d", name, cfg.WaitTimer, maxEnvironmentWaitTimer))
+		}
+
+		if cfg.ProtectedBranchesOnly && len(cfg.BranchPolicies) > 0 {
+			errs = append(errs, fmt.Errorf("environment %q: protected branches only and branch policies can't both be set", name))
+		}
+	}
+
+	for _, pattern := range m.ProtectedRefs {
+		if _, err := glob.Compile(pattern); err != nil {
+			errs = append(errs, fmt.Errorf("protected refs: %w", err))
+		}
+	}
+
+	return errs
+}
+
+// check validates the manifest, and every job deploying to an environment against it
+func (m *EnvironmentsManifest) check(workflows []Workflow) []error {
+	if m == nil {
+		return nil
+	}
+
+	errs := m.validate()
+	for _, w := range workflows {
+		filename := w.GetFilename()
+		for _, jobID := range sortedJobIDs(w) {
+			name := w.Jobs[jobID].Environment.Name
+			if name == "" || strings.Contains(name, "${{") {
+				continue
+			}
+
+			path := fmt.Sprintf("%s: %s.Environment", filename, jobPath(jobID))
+			if _, ok := m.Environments[name]; !ok {
+				errs = append(errs, fmt.Errorf("%s: no environment named %q, expected one of %s", path, name, strings.Join(slices.Sorted(maps.Keys(m.Environments)), ", ")))
+				continue
+			}
+
+			allowed := m.allowedRefs(name)
+			if allowed == nil && (name == ProductionEnvironment || m.Environments[name].ProtectedBranchesOnly) {
+				errs = append(errs, fmt.Errorf("%s: %q only accepts protected refs, but the manifest has no protected refs", path, name))
+				continue
+			}
+
+			if allowed == nil {
+				continue
+			}
+
+			refs, unrestricted := w.jobRefs(jobID)
+			for _, reason := range unrestricted {
+				errs = append(errs, fmt.Errorf("%s: %q only accepts %s, but %s", path, name, strings.Join(allowed, ", "), reason))
+			}
+
+			for _, ref := range refs {
+				if !refAllowed(ref, allowed) {
+					errs = append(errs, fmt.Errorf("%s: %q only accepts %s, but the job runs on %s", path, name, strings.Join(allowed, ", "), ref))
+				}
+			}
+		}
+	}
+
+	return errs
+}
+
+// refAllowed reports whether every ref matched by the trigger pattern ref is matched by an allowed pattern,
+// which is approximated by the allowed pattern matching the trigger pattern itself
+func refAllowed(ref string, allowed []string) bool {
+	for _, a := range allowed {
+		if a == ref {
+			return true
+		}
+
+		if p, err := glob.Compile(a); err == nil && !p.Negated && p.Match(ref) {
+			return true
+		}
+	}
+
+	return false
+}
+
+var jobRefConditionPattern = regexp.MustCompile(`^\s*(?:\$\{\{)?\s*github\.ref\s*==\s*'([^']+)'\s*(?:\}\})?\s*$`)
+
+// jobRefs returns the patterns of the refs a job can run on, and why that can't be told for some triggers.
+// A job condition of the form github.ref == 'refs/heads/main' takes precedence over the triggers.
+func (w Workflow) jobRefs(jobID string) (refs []string, unrestricted []string) {
+	if m := jobRefConditionPattern.FindStringSubmatch(w.Jobs[jobID].If); m != nil {
+		return []string{m[1]}, nil
+	}
+
+	on := w.On
+	if on.Push != nil {
+		branches, tags := on.Push.OnBranches, on.Push.OnTags
+		filtersBranches, filtersTags := branches.filtersBranches(), tags.filtersTags()
+
+		switch {
+		case filtersBranches && len(branches.BranchesIgnore) > 0:
+			unrestricted = append(unrestricted, "push only ignores some branches")
+		case filtersBranches:
+			refs = append(refs, prefixPatterns("refs/heads/", branches.Branches)...)
+		case !filtersTags:
+			unrestricted = append(unrestricted, "push has no branch filter")
+		}
+
+		switch {
+		case filtersTags && len(tags.TagsIgnore) > 0:
+			unrestricted = append(unrestricted, "push only ignores some tags")
+		case filtersTags:
+			refs = append(refs, prefixPatterns("refs/tags/", tags.Tags)...)
+		case !filtersBranches:
+			unrestricted = append(unrestricted, "push has no tag filter")
+		}
+	}
+
+	if on.PullRequest != nil {
+		unrestricted = append(unrestricted, "pull_request runs on the merge ref of the pull request")
+	}
+
+	if on.PullRequestTarget != nil {
+		unrestricted = append(unrestricted, "pull_request_target can be triggered by any pull request")
+	}
+
+	if on.Dispatch != nil {
+		unrestricted = append(unrestricted, "workflow_dispatch can run on any branch")
+	}
+
+	if on.Call != nil {
+		unrestricted = append(unrestricted, "workflow_call runs on the ref of the caller, which can be any")
+	}
+
+	// the triggers gocto has no field for, e.g. release or issue_comment, can't be told apart
+	for _, event := range slices.Sorted(maps.Keys(on.Extra)) {
+		unrestricted = append(unrestricted, fmt.Sprintf("%s runs on refs gocto can't tell", event))
+	}
+
+	// schedule and workflow_run run on the default branch
+
+	return refs, unrestricted
+}
+
+// prefixPatterns qualifies the positive patterns of a filter with a ref prefix
+func prefixPatterns(prefix string, patterns []string) []string {
+	var refs []string
+	for _, p := range patterns {
+		if !strings.HasPrefix(p, "!") {
+			refs = append(refs, prefix+strings.TrimPrefix(p, prefix))
+		}
+	}
+
+	return refs
+}
@@ -0,0 +1,116 @@
+package gocto
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cakehappens/gocto/expressions"
+)
+
+func TestEnvironmentJSON(t *testing.T) {
+	type testCase struct {
+		name     string
+		env      Environment
+		expected string
+	}
+
+	testCases := []testCase{
+		{
+			name:     "shorthand",
+			env:      Environment{Name: "staging"},
+			expected: `"staging"`,
+		},
+		{
+			name:     "url expression",
+			env:      Environment{Name: "production", URL: expressions.StepOutput("deploy", "url")},
+			expected: `{"name":"production","url":"${{steps.deploy.outputs.url}}"}`,
+		},
+		{
+			name:     "static url",
+			env:      Environment{Name: "docs", URL: expressions.FromTemplate("https://docs.example.com")},
+			expected: `{"name":"docs","url":"https://docs.example.com"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := json.Marshal(tc.env)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(b))
+
+			var env Environment
+			require.NoError(t, json.Unmarshal(b, &env))
+			assert.Equal(t, tc.env, env)
+		})
+	}
+}
+
+func TestEnvironmentsManifest(t *testing.T) {
+	job := func(env, cond string) Job {
+		return Job{
+			If:          cond,
+			RunsOn:      StringOrSlice{"ubuntu-latest"},
+			Environment: Environment{Name: env},
+			Steps:       []Step{{Run: "make deploy"}},
+		}
+	}
+
+	p := NewProject().AddWorkflow(
+		Workflow{
+			Name: "Release",
+			On: WorkflowOn{
+				Push: &OnPush{
+					OnBranches: &OnBranches{Branches: []string{"main"}},
+					OnTags:     &OnTags{Tags: []string{"v*"}},
+				},
+			},
+			Jobs: map[string]Job{
+				"production": job("production", ""),
+				"preview":    job("preview", ""),
+				"unknown":    job("qa", ""),
+				"dynamic":    job("${{ inputs.environment }}", ""),
+			},
+		},
+		Workflow{
+			Name: "Deploy",
+			On: WorkflowOn{
+				Dispatch:    &OnDispatch{},
+				PullRequest: &OnPullRequest{},
+				Call:        &OnCall{},
+				Extra:       map[string]any{"release": map[string]any{"types": []any{"published"}}},
+			},
+			Jobs: map[string]Job{
+				"guarded":    job("production", "github.ref == 'refs/heads/main'"),
+				"production": job("production", ""),
+				"staging":    job("staging", ""),
+			},
+		},
+	)
+
+	p.Environments = &EnvironmentsManifest{
+		ProtectedRefs: []string{"refs/heads/main", "refs/tags/v*"},
+		Environments: map[string]EnvironmentConfig{
+			"production": {Reviewers: []string{"octo-org/release"}, WaitTimer: 10},
+			"preview":    {BranchPolicies: []string{"refs/heads/preview/*"}},
+			"staging":    {WaitTimer: 50000},
+		},
+	}
+
+	_, err := p.ResolvedWorkflows()
+	require.Error(t, err)
+
+	assert.Equal(t, []string{
+		`environment "staging": wait timer of 50000 minutes, expected 0 to 43200`,
+		`release.yml: Jobs["preview"].Environment: "preview" only accepts refs/heads/preview/*, but the job runs on refs/heads/main`,
+		`release.yml: Jobs["preview"].Environment: "preview" only accepts refs/heads/preview/*, but the job runs on refs/tags/v*`,
+		`release.yml: Jobs["unknown"].Environment: no environment named "qa", expected one of preview, production, staging`,
+		`deploy.yml: Jobs["production"].Environment: "production" only accepts refs/heads/main, refs/tags/v*, but pull_request runs on the merge ref of the pull request`,
+		`deploy.yml: Jobs["production"].Environment: "production" only accepts refs/heads/main, refs/tags/v*, but workflow_dispatch can run on any branch`,
+		`deploy.yml: Jobs["production"].Environment: "production" only accepts refs/heads/main, refs/tags/v*, but workflow_call runs on the ref of the caller, which can be any`,
+		`deploy.yml: Jobs["production"].Environment: "production" only accepts refs/heads/main, refs/tags/v*, but release runs on refs gocto can't tell`,
+	}, strings.Split(err.Error(), "\n"))
+}
@@ -0,0 +1,498 @@
+package expressions
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Status is the outcome so far of the job, or of the needs of a job,
+// which the status check functions success(), failure() and cancelled() report on
+type Status string
+
+const (
+	StatusSuccess   Status = "success"
+	StatusFailure   Status = "failure"
+	StatusCancelled Status = "cancelled"
+	// StatusSkipped is only used for the needs of a job when one of them was skipped,
+	// none of success(), failure() or cancelled() is true
+	StatusSkipped Status = "skipped"
+)
+
+// Function is a function callable from an expression, e.g. hashFiles
+type Function func(args ...any) (any, error)
+
+// Evaluator evaluates expressions against a set of contexts.
+//
+// Values follow the JSON data model: nil, bool, float64, string, []any and map[string]any.
+// Other Go values in Contexts are converted through encoding/json.
+type Evaluator struct {
+	// Contexts are keyed by name, e.g. github, env, matrix, steps
+	Contexts map[string]any
+	// Status is reported by the status check functions, the zero value is StatusSuccess
+	Status Status
+	// Functions extend or replace the built-in functions, keyed by lowercase name
+	Functions map[string]Function
+}
+
+// Evaluate evaluates a single expression, without the surrounding ${{ }}
+func (e *Evaluator) Evaluate(expr string) (any, error) {
+	n, err := parse(expr)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %q: %w", expr, err)
+	}
+
+	v, err := e.eval(n)
+	if err != nil {
+		return nil, fmt.Errorf("evaluating %q: %w", expr, err)
+	}
+
+	return v, nil
+}
+
+// EvaluateCondition evaluates an if condition the way the runner does:
+// the surrounding ${{ }} is optional, and conditions which don't call a status check function
+// are implicitly success() && ( condition )
+func (e *Evaluator) EvaluateCondition(cond string) (bool, error) {
+	cond = strings.TrimSpace(cond)
+	if cond == "" {
+		cond = "success()"
+	}
+
+	if strings.HasPrefix(cond, "${{") && strings.HasSuffix(cond, "}}") && strings.Count(cond, "${{") == 1 {
+		cond = strings.TrimSpace(cond[3 : len(cond)-2])
+	}
+
+	n, err := parse(cond)
+	if err != nil {
+		return false, fmt.Errorf("parsing %q: %w", cond, err)
+	}
+
+	if !callsStatusFunction(n) {
+		n = binaryNode{op: "&&", left: callNode{name: "success"}, right: n}
+	}
+
+	v, err := e.eval(n)
+	if err != nil {
+		return false, fmt.Errorf("evaluating %q: %w", cond, err)
+	}
+
+	return IsTruthy(v), nil
+}
+
+// Interpolate replaces every ${{ }} in s with the string form of its value
+func (e *Evaluator) Interpolate(s string) (string, error) {
+	var sb strings.Builder
+	for {
+		start := strings.Index(s, "${{")
+		if start < 0 {
+			sb.WriteString(s)
+			return sb.String(), nil
+		}
+
+		end := expressionEnd(s, start+3)
+		if end < 0 {
+			return "", fmt.Errorf("unterminated expression in %q", s)
+		}
+
+		v, err := e.Evaluate(s[start+3 : end])
+		if err != nil {
+			return "", err
+		}
+
+		sb.WriteString(s[:start])
+		sb.WriteString(ToString(v))
+		s = s[end+2:]
+	}
+}
+
+// expressionEnd finds the }} closing an expression, ignoring any inside string literals
+func expressionEnd(s string, from int) int {
+	inString := false
+	for i := from; i < len(s); i++ {
+		switch {
+		case s[i] == '\'':
+			inString = !inString
+		case !inString && strings.HasPrefix(s[i:], "}}"):
+			return i
+		}
+	}
+
+	return -1
+}
+
+var statusFunctions = map[string]bool{
+	"success":   true,
+	"failure":   true,
+	"cancelled": true,
+	"always":    true,
+}
+
+func callsStatusFunction(n node) bool {
+	switch n := n.(type) {
+	case callNode:
+		if statusFunctions[n.name] {
+			return true
+		}
+		for _, arg := range n.args {
+			if callsStatusFunction(arg) {
+				return true
+			}
+		}
+	case propertyNode:
+		return callsStatusFunction(n.target)
+	case indexNode:
+		return callsStatusFunction(n.target) || callsStatusFunction(n.index)
+	case filterNode:
+		return callsStatusFunction(n.target)
+	case notNode:
+		return callsStatusFunction(n.operand)
+	case binaryNode:
+		return callsStatusFunction(n.left) || callsStatusFunction(n.right)
+	}
+
+	return false
+}
+
+func (e *Evaluator) eval(n node) (any, error) {
+	switch n := n.(type) {
+	case literalNode:
+		return n.value, nil
+	case contextNode:
+		v, _ := lookup(e.Contexts, n.name)
+		return normalize(v), nil
+	case propertyNode:
+		target, err := e.eval(n.target)
+		if err != nil {
+			return nil, err
+		}
+		return property(target, n.name), nil
+	case indexNode:
+		target, err := e.eval(n.target)
+		if err != nil {
+			return nil, err
+		}
+		index, err := e.eval(n.index)
+		if err != nil {
+			return nil, err
+		}
+		return indexValue(target, index), nil
+	case filterNode:
+		target, err := e.eval(n.target)
+		if err != nil {
+			return nil, err
+		}
+		return filter(target), nil
+	case notNode:
+		v, err := e.eval(n.operand)
+		if err != nil {
+			return nil, err
+		}
+		return !IsTruthy(v), nil
+	case binaryNode:
+		return e.evalBinary(n)
+	case callNode:
+		args := make([]any, 0, len(n.args))
+		for _, arg := range n.args {
+			v, err := e.eval(arg)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, v)
+		}
+		return e.call(n.name, args)
+	}
+
+	return nil, fmt.Errorf("unknown node %T", n)
+}
+
+func (e *Evaluator) evalBinary(n binaryNode) (any, error) {
+	left, err := e.eval(n.left)
+	if err != nil {
+		return nil, err
+	}
+
+	// && and || short circuit, and evaluate to one of their operands rather than a bool
+	switch n.op {
+	case "&&":
+		if !IsTruthy(left) {
+			return left, nil
+		}
+		return e.eval(n.right)
+	case "||":
+		if IsTruthy(left) {
+			return left, nil
+		}
+		return e.eval(n.right)
+	}
+
+	right, err := e.eval(n.right)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return looseEquals(left, right), nil
+	case "!=":
+		return !looseEquals(left, right), nil
+	}
+
+	cmp, ok := looseCompare(left, right)
+	if !ok {
+		return false, nil
+	}
+
+	switch n.op {
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	case ">=":
+		return cmp >= 0, nil
+	}
+
+	return nil, fmt.Errorf("unknown operator %q", n.op)
+}
+
+// lookup finds a key, falling back to a case-insensitive match like the runner does
+func lookup(m map[string]any, key string) (any, bool) {
+	if v, ok := m[key]; ok {
+		return v, true
+	}
+
+	for k, v := range m {
+		if strings.EqualFold(k, key) {
+			return v, true
+		}
+	}
+
+	return nil, false
+}
+
+func property(target any, name string) any {
+	switch t := target.(type) {
+	case map[string]any:
+		v, _ := lookup(t, name)
+		return normalize(v)
+	case []any:
+		// property access through a filter applies to every element
+		var out []any
+		for _, el := range t {
+			if m, ok := el.(map[string]any); ok {
+				if v, ok := lookup(m, name); ok {
+					out = append(out, normalize(v))
+				}
+			}
+		}
+		return out
+	}
+
+	return nil
+}
+
+func indexValue(target, index any) any {
+	switch t := target.(type) {
+	case map[string]any:
+		return property(t, ToString(index))
+	case []any:
+		i := ToNumber(index)
+		if math.IsNaN(i) || i < 0 || int(i) >= len(t) {
+			return nil
+		}
+		return normalize(t[int(i)])
+	}
+
+	return nil
+}
+
+func filter(target any) any {
+	switch t := target.(type) {
+	case map[string]any:
+		out := make([]any, 0, len(t))
+		for _, v := range t {
+			out = append(out, normalize(v))
+		}
+		return out
+	case []any:
+		return t
+	}
+
+	return []any{}
+}
+
+// normalize converts Go values into the JSON data model the evaluator works with
+func normalize(v any) any {
+	switch v := v.(type) {
+	case nil, bool, float64, string, []any, map[string]any:
+		return v
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case Expression:
+		return string(v)
+	}
+
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+
+	var out any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil
+	}
+
+	return out
+}
+
+// IsTruthy coerces a value the way conditionals do, see alwaysFalseVals
+func IsTruthy(v any) bool {
+	switch v := normalize(v).(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case float64:
+		return v != 0 && !math.IsNaN(v)
+	case string:
+		return v != ""
+	}
+
+	return true
+}
+
+// ToNumber coerces a value to a number, values which can't be coerced are NaN
+func ToNumber(v any) float64 {
+	switch v := normalize(v).(type) {
+	case nil:
+		return 0
+	case bool:
+		if v {
+			return 1
+		}
+		return 0
+	case float64:
+		return v
+	case string:
+		s := strings.TrimSpace(v)
+		if s == "" {
+			return 0
+		}
+		if n, err := parseNumber(s); err == nil {
+			return n
+		}
+	}
+
+	return math.NaN()
+}
+
+// ToString converts a value to the string interpolation produces
+func ToString(v any) string {
+	switch v := normalize(v).(type) {
+	case nil:
+		return ""
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return formatNumber(v)
+	case string:
+		return v
+	case []any:
+		return "Array"
+	case map[string]any:
+		return "Object"
+	}
+
+	return ""
+}
+
+func formatNumber(f float64) string {
+	if math.IsNaN(f) {
+		return "NaN"
+	}
+	if math.IsInf(f, 0) {
+		if f > 0 {
+			return "Infinity"
+		}
+		return "-Infinity"
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func isPrimitive(v any) bool {
+	switch v.(type) {
+	case nil, bool, float64, string:
+		return true
+	}
+	return false
+}
+
+// looseEquals compares like ==: strings case-insensitively, mixed primitive types as numbers,
+// and objects and arrays only by identity
+func looseEquals(a, b any) bool {
+	a, b = normalize(a), normalize(b)
+
+	if !isPrimitive(a) || !isPrimitive(b) {
+		if !isPrimitive(a) && !isPrimitive(b) {
+			return reflect.ValueOf(a).UnsafePointer() == reflect.ValueOf(b).UnsafePointer()
+		}
+		return false
+	}
+
+	if a == nil && b == nil {
+		return true
+	}
+
+	if as, ok := a.(string); ok {
+		if bs, ok := b.(string); ok {
+			return strings.EqualFold(as, bs)
+		}
+	}
+
+	if ab, ok := a.(bool); ok {
+		if bb, ok := b.(bool); ok {
+			return ab == bb
+		}
+	}
+
+	an, bn := ToNumber(a), ToNumber(b)
+	return !math.IsNaN(an) && !math.IsNaN(bn) && an == bn
+}
+
+// looseCompare orders two primitives, ok is false when they can't be ordered
+func looseCompare(a, b any) (int, bool) {
+	a, b = normalize(a), normalize(b)
+	if !isPrimitive(a) || !isPrimitive(b) {
+		return 0, false
+	}
+
+	if as, ok := a.(string); ok {
+		if bs, ok := b.(string); ok {
+			return strings.Compare(strings.ToLower(as), strings.ToLower(bs)), true
+		}
+	}
+
+	an, bn := ToNumber(a), ToNumber(b)
+	if math.IsNaN(an) || math.IsNaN(bn) {
+		return 0, false
+	}
+
+	switch {
+	case an < bn:
+		return -1, true
+	case an > bn:
+		return 1, true
+	}
+	return 0, true
+}
@@ -0,0 +1,102 @@
+package expressions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEvaluator() *Evaluator {
+	return &Evaluator{
+		Contexts: map[string]any{
+			"github": map[string]any{
+				"event_name": "push",
+				"ref":        "refs/tags/v1.2.3",
+				"event": map[string]any{
+					"commits": []any{
+						map[string]any{"message": "fix: one"},
+						map[string]any{"message": "feat: two"},
+					},
+				},
+			},
+			"matrix": map[string]int{
+				"shard": 2,
+			},
+			"steps": map[string]any{
+				"my-step": map[string]any{
+					"outputs": map[string]string{"value": "42"},
+				},
+			},
+		},
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	type testCase struct {
+		expr     string
+		expected any
+	}
+
+	cases := []testCase{
+		{expr: "github.event_name == 'PUSH'", expected: true},
+		{expr: "startsWith(github.ref, 'refs/tags/')", expected: true},
+		{expr: "github['ref']", expected: "refs/tags/v1.2.3"},
+		{expr: "steps.my-step.outputs.value == 42", expected: true},
+		{expr: "matrix.shard > 1 && 'yes' || 'no'", expected: "yes"},
+		{expr: "matrix.missing || 'default'", expected: "default"},
+		{expr: "!matrix.missing", expected: true},
+		{expr: "contains(github.event.commits.*.message, 'FEAT: TWO')", expected: true},
+		{expr: "join(github.event.commits.*.message, '; ')", expected: "fix: one; feat: two"},
+		{expr: "format('{0}-{1} {{x}}', 'a', 1.5)", expected: "a-1.5 {x}"},
+		{expr: "fromJSON('{\"a\":[1,2]}').a[1]", expected: float64(2)},
+		{expr: "toJSON(matrix)", expected: "{\n  \"shard\": 2\n}"},
+		{expr: "0xff == 255", expected: true},
+		{expr: "'it''s'", expected: "it's"},
+		{expr: "null == false", expected: true},
+		{expr: "'abc' < 'ABD'", expected: true},
+		{expr: "-1.5e1", expected: float64(-15)},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.expr, func(t *testing.T) {
+			v, err := testEvaluator().Evaluate(tc.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, v)
+		})
+	}
+}
+
+func TestEvaluateErrors(t *testing.T) {
+	for _, expr := range []string{"github.", "'unterminated", "nope()", "(a", "a b"} {
+		_, err := testEvaluator().Evaluate(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestEvaluateCondition(t *testing.T) {
+	e := testEvaluator()
+
+	ok, err := e.EvaluateCondition("${{ github.event_name == 'push' }}")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	e.Status = StatusFailure
+	ok, err = e.EvaluateCondition("github.event_name == 'push'")
+	require.NoError(t, err)
+	assert.False(t, ok, "conditions without a status function are implicitly success() &&")
+
+	ok, err = e.EvaluateCondition("failure() && github.event_name == 'push'")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = e.EvaluateCondition("always()")
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestInterpolate(t *testing.T) {
+	s, err := testEvaluator().Interpolate("shard ${{ matrix.shard }} of ${{ format('{0}}}', 'x') }}")
+	require.NoError(t, err)
+	assert.Equal(t, "shard 2 of x}", s)
+}
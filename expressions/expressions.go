@@ -113,3 +113,11 @@ func Secrets(val string) Expression {
 func StepOutput(stepID, outputKey string) Expression {
 	return Expression("steps." + stepID + ".outputs." + outputKey)
 }
+
+func NeedsOutput(jobID, outputKey string) Expression {
+	return Expression("needs." + jobID + ".outputs." + outputKey)
+}
+
+func NeedsResult(jobID string) Expression {
+	return Expression("needs." + jobID + ".result")
+}
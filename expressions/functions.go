@@ -0,0 +1,134 @@
+package expressions
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// https://docs.github.com/en/actions/reference/evaluate-expressions-in-workflows-and-actions#functions
+
+func (e *Evaluator) call(name string, args []any) (any, error) {
+	if fn, ok := e.Functions[name]; ok {
+		return fn(args...)
+	}
+
+	status := e.Status
+	if status == "" {
+		status = StatusSuccess
+	}
+
+	switch name {
+	case "success":
+		return status == StatusSuccess, checkArgs(name, args, 0, 0)
+	case "failure":
+		return status == StatusFailure, checkArgs(name, args, 0, 0)
+	case "cancelled":
+		return status == StatusCancelled, checkArgs(name, args, 0, 0)
+	case "always":
+		return true, checkArgs(name, args, 0, 0)
+	case "contains":
+		if err := checkArgs(name, args, 2, 2); err != nil {
+			return nil, err
+		}
+		if arr, ok := args[0].([]any); ok {
+			for _, el := range arr {
+				if looseEquals(el, args[1]) {
+					return true, nil
+				}
+			}
+			return false, nil
+		}
+		return strings.Contains(strings.ToLower(ToString(args[0])), strings.ToLower(ToString(args[1]))), nil
+	case "startswith":
+		if err := checkArgs(name, args, 2, 2); err != nil {
+			return nil, err
+		}
+		return strings.HasPrefix(strings.ToLower(ToString(args[0])), strings.ToLower(ToString(args[1]))), nil
+	case "endswith":
+		if err := checkArgs(name, args, 2, 2); err != nil {
+			return nil, err
+		}
+		return strings.HasSuffix(strings.ToLower(ToString(args[0])), strings.ToLower(ToString(args[1]))), nil
+	case "format":
+		if err := checkArgs(name, args, 1, -1); err != nil {
+			return nil, err
+		}
+		return format(ToString(args[0]), args[1:])
+	case "join":
+		if err := checkArgs(name, args, 1, 2); err != nil {
+			return nil, err
+		}
+		sep := ","
+		if len(args) == 2 {
+			sep = ToString(args[1])
+		}
+		arr, ok := args[0].([]any)
+		if !ok {
+			return ToString(args[0]), nil
+		}
+		parts := make([]string, 0, len(arr))
+		for _, el := range arr {
+			parts = append(parts, ToString(el))
+		}
+		return strings.Join(parts, sep), nil
+	case "tojson":
+		if err := checkArgs(name, args, 1, 1); err != nil {
+			return nil, err
+		}
+		data, err := json.MarshalIndent(args[0], "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return string(data), nil
+	case "fromjson":
+		if err := checkArgs(name, args, 1, 1); err != nil {
+			return nil, err
+		}
+		var v any
+		if err := json.Unmarshal([]byte(ToString(args[0])), &v); err != nil {
+			return nil, fmt.Errorf("fromJSON: %w", err)
+		}
+		return v, nil
+	}
+
+	return nil, fmt.Errorf("unknown function %s()", name)
+}
+
+func checkArgs(name string, args []any, minArgs, maxArgs int) error {
+	if len(args) < minArgs || maxArgs >= 0 && len(args) > maxArgs {
+		return fmt.Errorf("%s() called with %d arguments", name, len(args))
+	}
+	return nil
+}
+
+// format replaces {N} with the Nth argument, {{ and }} escape braces
+func format(f string, args []any) (string, error) {
+	var sb strings.Builder
+	for i := 0; i < len(f); i++ {
+		switch {
+		case strings.HasPrefix(f[i:], "{{"):
+			sb.WriteByte('{')
+			i++
+		case strings.HasPrefix(f[i:], "}}"):
+			sb.WriteByte('}')
+			i++
+		case f[i] == '{':
+			end := strings.IndexByte(f[i:], '}')
+			if end < 0 {
+				return "", fmt.Errorf("format: unclosed { in %q", f)
+			}
+			n, err := strconv.Atoi(f[i+1 : i+end])
+			if err != nil || n < 0 || n >= len(args) {
+				return "", fmt.Errorf("format: invalid placeholder %q in %q", f[i:i+end+1], f)
+			}
+			sb.WriteString(ToString(args[n]))
+			i += end
+		default:
+			sb.WriteByte(f[i])
+		}
+	}
+
+	return sb.String(), nil
+}
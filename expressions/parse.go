@@ -0,0 +1,422 @@
+package expressions
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// https://docs.github.com/en/actions/reference/evaluate-expressions-in-workflows-and-actions
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNull
+	tokenBool
+	tokenNumber
+	tokenString
+	tokenIdent
+	tokenPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	num  float64
+	pos  int
+}
+
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '\'':
+			var sb strings.Builder
+			j := i + 1
+			for {
+				if j >= len(expr) {
+					return nil, fmt.Errorf("unterminated string starting at %d", i)
+				}
+				if expr[j] == '\'' {
+					// '' is an escaped quote
+					if j+1 < len(expr) && expr[j+1] == '\'' {
+						sb.WriteByte('\'')
+						j += 2
+						continue
+					}
+					break
+				}
+				sb.WriteByte(expr[j])
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenString, text: sb.String(), pos: i})
+			i = j + 1
+		case strings.ContainsRune("!=<>", rune(c)) && i+1 < len(expr) && expr[i+1] == '=':
+			tokens = append(tokens, token{kind: tokenPunct, text: expr[i : i+2], pos: i})
+			i += 2
+		case (c == '&' || c == '|') && i+1 < len(expr) && expr[i+1] == c:
+			tokens = append(tokens, token{kind: tokenPunct, text: expr[i : i+2], pos: i})
+			i += 2
+		case strings.ContainsRune("()[].,!<>*", rune(c)):
+			// a . followed by a digit starts a number, e.g. .5
+			if c == '.' && i+1 < len(expr) && isDigit(expr[i+1]) && !afterOperand(tokens) {
+				j := scanNumber(expr, i)
+				num, err := parseNumber(expr[i:j])
+				if err != nil {
+					return nil, err
+				}
+				tokens = append(tokens, token{kind: tokenNumber, text: expr[i:j], num: num, pos: i})
+				i = j
+				continue
+			}
+			tokens = append(tokens, token{kind: tokenPunct, text: string(c), pos: i})
+			i++
+		case isDigit(c) || (c == '-' || c == '+') && !afterOperand(tokens):
+			j := scanNumber(expr, i)
+			num, err := parseNumber(expr[i:j])
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q at %d", expr[i:j], i)
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: expr[i:j], num: num, pos: i})
+			i = j
+		case isIdentStart(c):
+			j := i + 1
+			for j < len(expr) && isIdentPart(expr[j]) {
+				j++
+			}
+			word := expr[i:j]
+			switch word {
+			case "null":
+				tokens = append(tokens, token{kind: tokenNull, text: word, pos: i})
+			case "true", "false":
+				tokens = append(tokens, token{kind: tokenBool, text: word, pos: i})
+			default:
+				tokens = append(tokens, token{kind: tokenIdent, text: word, pos: i})
+			}
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected character %q at %d", c, i)
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(expr)}), nil
+}
+
+// afterOperand reports whether the previous token ends an operand,
+// in which case a following . is property access rather than a number
+func afterOperand(tokens []token) bool {
+	if len(tokens) == 0 {
+		return false
+	}
+
+	last := tokens[len(tokens)-1]
+	switch last.kind {
+	case tokenPunct:
+		return last.text == ")" || last.text == "]" || last.text == "*"
+	default:
+		return true
+	}
+}
+
+func scanNumber(expr string, i int) int {
+	j := i
+	if expr[j] == '-' || expr[j] == '+' {
+		j++
+	}
+
+	if strings.HasPrefix(expr[j:], "0x") || strings.HasPrefix(expr[j:], "0X") {
+		j += 2
+		for j < len(expr) && isHexDigit(expr[j]) {
+			j++
+		}
+		return j
+	}
+
+	for j < len(expr) && (isDigit(expr[j]) || expr[j] == '.') {
+		j++
+	}
+
+	if j < len(expr) && (expr[j] == 'e' || expr[j] == 'E') {
+		j++
+		if j < len(expr) && (expr[j] == '-' || expr[j] == '+') {
+			j++
+		}
+		for j < len(expr) && isDigit(expr[j]) {
+			j++
+		}
+	}
+
+	return j
+}
+
+func parseNumber(text string) (float64, error) {
+	neg := strings.HasPrefix(text, "-")
+	unsigned := strings.TrimLeft(text, "-+")
+	if strings.HasPrefix(unsigned, "0x") || strings.HasPrefix(unsigned, "0X") {
+		n, err := strconv.ParseInt(unsigned[2:], 16, 64)
+		if err != nil {
+			return 0, err
+		}
+		if neg {
+			n = -n
+		}
+		return float64(n), nil
+	}
+
+	return strconv.ParseFloat(text, 64)
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || unicode.IsLetter(rune(c))
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || isDigit(c) || c == '-'
+}
+
+type node interface{}
+
+type (
+	literalNode  struct{ value any }
+	contextNode  struct{ name string }
+	propertyNode struct {
+		target node
+		name   string
+	}
+	indexNode struct {
+		target node
+		index  node
+	}
+	filterNode struct{ target node }
+	notNode    struct{ operand node }
+	binaryNode struct {
+		op          string
+		left, right node
+	}
+	callNode struct {
+		name string
+		args []node
+	}
+)
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// parse parses an expression, without the surrounding ${{ }}
+func parse(expr string) (node, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at %d", t.text, t.pos)
+	}
+
+	return n, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) accept(punct string) bool {
+	if t := p.peek(); t.kind == tokenPunct && t.text == punct {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(punct string) error {
+	if !p.accept(punct) {
+		t := p.peek()
+		if t.kind == tokenEOF {
+			return fmt.Errorf("expected %q at end of expression", punct)
+		}
+		return fmt.Errorf("expected %q at %d, found %q", punct, t.pos, t.text)
+	}
+	return nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	return p.parseBinary(p.parseAnd, "||")
+}
+
+func (p *parser) parseAnd() (node, error) {
+	return p.parseBinary(p.parseEquality, "&&")
+}
+
+func (p *parser) parseEquality() (node, error) {
+	return p.parseBinary(p.parseComparison, "==", "!=")
+}
+
+func (p *parser) parseComparison() (node, error) {
+	return p.parseBinary(p.parseUnary, "<", "<=", ">", ">=")
+}
+
+func (p *parser) parseBinary(operand func() (node, error), ops ...string) (node, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		t := p.peek()
+		if t.kind != tokenPunct || !contains(ops, t.text) {
+			return left, nil
+		}
+		p.next()
+
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+
+		left = binaryNode{op: t.text, left: left, right: right}
+	}
+}
+
+func contains(vals []string, val string) bool {
+	for _, v := range vals {
+		if v == val {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.accept("!") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand: operand}, nil
+	}
+
+	return p.parsePostfix()
+}
+
+func (p *parser) parsePostfix() (node, error) {
+	n, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		switch {
+		case p.accept("."):
+			if p.accept("*") {
+				n = filterNode{target: n}
+				continue
+			}
+
+			t := p.next()
+			if t.kind == tokenEOF || t.kind == tokenPunct || t.kind == tokenString || t.kind == tokenNumber {
+				return nil, fmt.Errorf("expected property name at %d", t.pos)
+			}
+			n = propertyNode{target: n, name: t.text}
+		case p.accept("["):
+			if p.accept("*") {
+				if err := p.expect("]"); err != nil {
+					return nil, err
+				}
+				n = filterNode{target: n}
+				continue
+			}
+
+			index, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			n = indexNode{target: n, index: index}
+		default:
+			return n, nil
+		}
+	}
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenNull:
+		return literalNode{value: nil}, nil
+	case tokenBool:
+		return literalNode{value: t.text == "true"}, nil
+	case tokenNumber:
+		return literalNode{value: t.num}, nil
+	case tokenString:
+		return literalNode{value: t.text}, nil
+	case tokenIdent:
+		if !p.accept("(") {
+			return contextNode{name: t.text}, nil
+		}
+
+		var args []node
+		if !p.accept(")") {
+			for {
+				arg, err := p.parseOr()
+				if err != nil {
+					return nil, err
+				}
+				args = append(args, arg)
+
+				if p.accept(")") {
+					break
+				}
+				if err := p.expect(","); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return callNode{name: strings.ToLower(t.text), args: args}, nil
+	case tokenPunct:
+		if t.text == "(" {
+			n, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return n, nil
+		}
+	case tokenEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+
+	return nil, fmt.Errorf("unexpected %q at %d", t.text, t.pos)
+}
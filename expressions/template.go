@@ -0,0 +1,78 @@
+package expressions
+
+import (
+	"strconv"
+	"strings"
+)
+
+// quote is a string literal as expressions write them, in single quotes with every ' doubled
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// Format calls format, {0} in template is replaced by the first argument, {1} by the second and so on
+// https://docs.github.com/en/actions/reference/evaluate-expressions-in-workflows-and-actions#format
+func Format(template string, args ...Expression) Expression {
+	parts := []string{quote(template)}
+	for _, arg := range args {
+		parts = append(parts, string(arg))
+	}
+
+	return Expression("format(" + strings.Join(parts, ", ") + ")")
+}
+
+// FromTemplate turns a workflow value, which can contain any number of ${{ }}, into a single expression.
+// A value which is a single ${{ }} becomes its contents, a value without any becomes a string literal,
+// and anything else a call to format.
+func FromTemplate(s string) Expression {
+	if s == "" {
+		return ""
+	}
+
+	var template strings.Builder
+	var args []Expression
+	escape := strings.NewReplacer("{", "{{", "}", "}}")
+
+	rest := s
+	for {
+		start := strings.Index(rest, "${{")
+		if start < 0 {
+			break
+		}
+
+		end := expressionEnd(rest, start+3)
+		if end < 0 {
+			break
+		}
+
+		if start == 0 && end+2 == len(rest) && len(args) == 0 {
+			return Expression(rest[3:end])
+		}
+
+		template.WriteString(escape.Replace(rest[:start]))
+		template.WriteString("{" + strconv.Itoa(len(args)) + "}")
+		args = append(args, Expression(strings.TrimSpace(rest[start+3:end])))
+		rest = rest[end+2:]
+	}
+
+	if len(args) == 0 {
+		return Expression(quote(s))
+	}
+
+	template.WriteString(escape.Replace(rest))
+	return Format(template.String(), args...)
+}
+
+// Template is the workflow value of the expression, the inverse of FromTemplate:
+// a string literal is written as is, anything else is wrapped in ${{ }}
+func (e Expression) Template() string {
+	if n, err := parse(string(e)); err == nil {
+		if lit, ok := n.(literalNode); ok {
+			if s, ok := lit.value.(string); ok {
+				return s
+			}
+		}
+	}
+
+	return e.String()
+}
@@ -0,0 +1,47 @@
+package expressions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromTemplate(t *testing.T) {
+	type testCase struct {
+		template string
+		expected Expression
+	}
+
+	testCases := []testCase{
+		{template: "", expected: ""},
+		{template: "${{ steps.deploy.outputs.url }}", expected: " steps.deploy.outputs.url "},
+		{template: "https://example.com/it's", expected: "'https://example.com/it''s'"},
+		{
+			template: "https://${{ steps.deploy.outputs.host }}/{path}/${{ github.sha }}",
+			expected: "format('https://{0}/{{path}}/{1}', steps.deploy.outputs.host, github.sha)",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.template, func(t *testing.T) {
+			e := FromTemplate(tc.template)
+			assert.Equal(t, tc.expected, e)
+
+			if tc.template != "" && tc.expected[0] != 'f' {
+				assert.Equal(t, tc.template, e.Template())
+			}
+		})
+	}
+
+	ev := &Evaluator{
+		Contexts: map[string]any{
+			"steps":  map[string]any{"deploy": map[string]any{"outputs": map[string]any{"host": "example.com"}}},
+			"github": map[string]any{"sha": "abc"},
+		},
+	}
+
+	v, err := ev.Evaluate(string(FromTemplate("https://${{ steps.deploy.outputs.host }}/{path}/${{ github.sha }}")))
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/{path}/abc", v)
+}
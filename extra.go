@@ -0,0 +1,400 @@
+package gocto
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+
+	"github.com/cakehappens/gocto/internal/util"
+)
+
+// ParseWorkflow reads a workflow document. Keys gocto has no field for, e.g. ones GitHub added since,
+// are kept in the Extra fields and rendered again.
+func ParseWorkflow(doc []byte) (Workflow, error) {
+	data, err := util.YAMLToJSON(doc)
+	if err != nil {
+		return Workflow{}, err
+	}
+
+	var w Workflow
+	if err := json.Unmarshal(data, &w); err != nil {
+		return Workflow{}, err
+	}
+
+	return w, nil
+}
+
+// ParseWorkflowStrict reads a workflow document like ParseWorkflow, but keys the workflow schema doesn't know are an error
+func ParseWorkflowStrict(doc []byte) (Workflow, error) {
+	w, err := ParseWorkflow(doc)
+	if err != nil {
+		return Workflow{}, err
+	}
+
+	var errs []error
+	for _, path := range w.UnknownFields() {
+		errs = append(errs, fmt.Errorf("%s: unknown key", path))
+	}
+
+	return w, errors.Join(errs...)
+}
+
+// UnknownFields returns the paths of the keys kept in the Extra fields which the workflow schema doesn't know either,
+// e.g. Jobs["build"].Extra["snapshot"]. Keys of the schema gocto has no field for, e.g. on: release, are not returned.
+func (w Workflow) UnknownFields() []string {
+	unknown := w.schemaUnknownKeys()
+
+	var paths []string
+	add := func(prefix string, pointer []string, extra map[string]any) {
+		for _, k := range slices.Sorted(maps.Keys(extra)) {
+			if unknown == nil || unknown[jsonPointer(append(slices.Clip(pointer), k))] {
+				paths = append(paths, fmt.Sprintf("%sExtra[%q]", prefix, k))
+			}
+		}
+	}
+
+	on := []string{"on"}
+	add("", nil, w.Extra)
+	add("On.", on, w.On.Extra)
+	if w.On.Call != nil {
+		add("On.Call.", append(on, "workflow_call"), w.On.Call.Extra)
+	}
+	if w.On.Run != nil {
+		add("On.Run.", append(on, "workflow_run"), w.On.Run.Extra)
+	}
+	if w.On.Dispatch != nil {
+		add("On.Dispatch.", append(on, "workflow_dispatch"), w.On.Dispatch.Extra)
+	}
+	if w.On.PullRequest != nil {
+		add("On.PullRequest.", append(on, "pull_request"), w.On.PullRequest.Extra)
+	}
+	if w.On.PullRequestTarget != nil {
+		add("On.PullRequestTarget.", append(on, "pull_request_target"), w.On.PullRequestTarget.Extra)
+	}
+	if w.On.Push != nil {
+		add("On.Push.", append(on, "push"), w.On.Push.Extra)
+	}
+
+	for _, jobID := range sortedJobIDs(w) {
+		job := w.Jobs[jobID]
+		add(jobPath(jobID)+".", []string{"jobs", jobID}, job.Extra)
+		for i, s := range job.Steps {
+			add(stepPath(jobID, i)+".", []string{"jobs", jobID, "steps", strconv.Itoa(i)}, s.Extra)
+		}
+	}
+
+	return paths
+}
+
+// schemaUnknownKeys returns the JSON pointers of the keys of the rendered workflow the schema doesn't allow,
+// or nil when the workflow can't be checked, then every key in an Extra field counts as unknown
+func (w Workflow) schemaUnknownKeys() map[string]bool {
+	sch, err := workflowSchema()
+	if err != nil {
+		return nil
+	}
+
+	data, err := json.Marshal(w)
+	if err != nil {
+		return nil
+	}
+
+	inst, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return nil
+	}
+
+	unknown := make(map[string]bool)
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(sch.Validate(inst), &validationErr) {
+		return unknown
+	}
+
+	for _, leaf := range schemaLeaves(validationErr) {
+		if additional, ok := leaf.ErrorKind.(*kind.AdditionalProperties); ok {
+			for _, k := range additional.Properties {
+				unknown[jsonPointer(append(slices.Clip(leaf.InstanceLocation), k))] = true
+			}
+		}
+	}
+
+	return unknown
+}
+
+// unmarshalExtra decodes data into v, a pointer to a struct type without an UnmarshalJSON method,
+// and returns the members of data none of its fields decode
+func unmarshalExtra(data []byte, v any) (map[string]any, error) {
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, err
+	}
+
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return nil, err
+	}
+
+	known := jsonFieldNames(reflect.TypeOf(v).Elem())
+	var extra map[string]any
+	for k, raw := range members {
+		if known[strings.ToLower(k)] {
+			continue
+		}
+
+		// numbers stay json.Number, so they are rendered exactly as they were read
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		var val any
+		if err := dec.Decode(&val); err != nil {
+			return nil, err
+		}
+
+		if extra == nil {
+			extra = make(map[string]any)
+		}
+		extra[k] = val
+	}
+
+	return extra, nil
+}
+
+// marshalExtra encodes v, a struct without a MarshalJSON method, and adds the extra members after its fields.
+// Members a field of v owns are left out, the field takes precedence.
+func marshalExtra(v any, extra map[string]any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || len(extra) == 0 {
+		return data, err
+	}
+
+	known := jsonFieldNames(reflect.TypeOf(v))
+	buf := bytes.NewBuffer(bytes.TrimSuffix(data, []byte("}")))
+	empty := len(data) == 2
+	for _, k := range slices.Sorted(maps.Keys(extra)) {
+		if known[strings.ToLower(k)] {
+			continue
+		}
+
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+
+		val, err := json.Marshal(extra[k])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", k, err)
+		}
+
+		if !empty {
+			buf.WriteByte(',')
+		}
+		empty = false
+
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// jsonFieldNames returns the lowercased names of the JSON members a struct type encodes,
+// including those of embedded structs. Matching is case insensitive like encoding/json.
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool)
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				maps.Copy(names, jsonFieldNames(ft))
+				continue
+			}
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+		names[strings.ToLower(name)] = true
+	}
+
+	return names
+}
+
+func (w *Workflow) UnmarshalJSON(data []byte) error {
+	data, err := scalarsAsText(data, "env")
+	if err != nil {
+		return err
+	}
+
+	type plain Workflow
+	extra, err := unmarshalExtra(data, (*plain)(w))
+	w.Extra = extra
+	return err
+}
+
+func (w Workflow) MarshalJSON() ([]byte, error) {
+	type plain Workflow
+	return marshalExtra(plain(w), w.Extra)
+}
+
+// UnmarshalJSON also accepts the event name shorthands, e.g. on: push or on: [push, pull_request],
+// and triggers without configuration, e.g. push: with a null value
+func (on *WorkflowOn) UnmarshalJSON(data []byte) error {
+	if util.IsJSONNull(data) {
+		return nil
+	}
+
+	triggers := make(map[string]json.RawMessage)
+	var events StringOrSlice
+	if err := json.Unmarshal(data, &events); err == nil {
+		for _, e := range events {
+			triggers[e] = nil
+		}
+	} else if err := json.Unmarshal(data, &triggers); err != nil {
+		return err
+	}
+
+	for k, v := range triggers {
+		if v == nil || util.IsJSONNull(v) {
+			triggers[k] = json.RawMessage("{}")
+		}
+	}
+
+	data, err := json.Marshal(triggers)
+	if err != nil {
+		return err
+	}
+
+	type plain WorkflowOn
+	extra, err := unmarshalExtra(data, (*plain)(on))
+	on.Extra = extra
+	return err
+}
+
+func (on WorkflowOn) MarshalJSON() ([]byte, error) {
+	type plain WorkflowOn
+	return marshalExtra(plain(on), on.Extra)
+}
+
+func (on *OnCall) UnmarshalJSON(data []byte) error {
+	type plain OnCall
+	extra, err := unmarshalExtra(data, (*plain)(on))
+	on.Extra = extra
+	return err
+}
+
+func (on OnCall) MarshalJSON() ([]byte, error) {
+	type plain OnCall
+	return marshalExtra(plain(on), on.Extra)
+}
+
+func (on *OnWorkflowRun) UnmarshalJSON(data []byte) error {
+	type plain OnWorkflowRun
+	extra, err := unmarshalExtra(data, (*plain)(on))
+	on.Extra = extra
+	return err
+}
+
+func (on OnWorkflowRun) MarshalJSON() ([]byte, error) {
+	type plain OnWorkflowRun
+	return marshalExtra(plain(on), on.Extra)
+}
+
+func (on *OnDispatch) UnmarshalJSON(data []byte) error {
+	type plain OnDispatch
+	extra, err := unmarshalExtra(data, (*plain)(on))
+	on.Extra = extra
+	return err
+}
+
+func (on OnDispatch) MarshalJSON() ([]byte, error) {
+	type plain OnDispatch
+	return marshalExtra(plain(on), on.Extra)
+}
+
+func (on *OnPullRequest) UnmarshalJSON(data []byte) error {
+	type plain OnPullRequest
+	extra, err := unmarshalExtra(data, (*plain)(on))
+	on.Extra = extra
+	return err
+}
+
+func (on OnPullRequest) MarshalJSON() ([]byte, error) {
+	type plain OnPullRequest
+	return marshalExtra(plain(on), on.Extra)
+}
+
+func (on *OnPush) UnmarshalJSON(data []byte) error {
+	type plain OnPush
+	extra, err := unmarshalExtra(data, (*plain)(on))
+	on.Extra = extra
+	return err
+}
+
+func (on OnPush) MarshalJSON() ([]byte, error) {
+	type plain OnPush
+	return marshalExtra(plain(on), on.Extra)
+}
+
+func (j *Job) UnmarshalJSON(data []byte) error {
+	data, err := scalarsAsText(data, "env", "with")
+	if err != nil {
+		return err
+	}
+
+	type plain Job
+	extra, err := unmarshalExtra(data, (*plain)(j))
+	j.Extra = extra
+	return err
+}
+
+// MarshalJSON renders the with: values reading as a number or boolean unquoted,
+// a reusable workflow rejects e.g. the string "true" for a boolean input
+func (j Job) MarshalJSON() ([]byte, error) {
+	type plain Job
+	data, err := marshalExtra(plain(j), j.Extra)
+	if err != nil || len(j.With) == 0 {
+		return data, err
+	}
+
+	return textAsScalars(data, "with", isCanonicalScalarText)
+}
+
+func (s *Step) UnmarshalJSON(data []byte) error {
+	data, err := scalarsAsText(data, "env")
+	if err != nil {
+		return err
+	}
+
+	type plain Step
+	extra, err := unmarshalExtra(data, (*plain)(s))
+	s.Extra = extra
+	return err
+}
+
+func (s Step) MarshalJSON() ([]byte, error) {
+	type plain Step
+	return marshalExtra(plain(s), s.Extra)
+}
@@ -0,0 +1,126 @@
+package gocto
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const extraTestDocument = `name: CI
+on:
+  push:
+    branches:
+      - main
+    future-filter: true
+  release:
+    types:
+      - published
+jobs:
+  build:
+    steps:
+      - run: make
+        retries: 3
+    snapshot:
+      image-name: ci
+      version: 1.10
+`
+
+func TestParseWorkflowExtra(t *testing.T) {
+	w, err := ParseWorkflow([]byte(extraTestDocument))
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]any{"future-filter": true}, w.On.Push.Extra)
+	assert.Equal(t, map[string]any{"release": map[string]any{"types": []any{"published"}}}, w.On.Extra)
+	assert.Equal(t, map[string]any{"retries": json.Number("3")}, w.Jobs["build"].Steps[0].Extra)
+	assert.Equal(t, map[string]any{"image-name": "ci", "version": json.Number("1.10")}, w.Jobs["build"].Extra["snapshot"])
+
+	// the schema knows release, and allows any key in the filters of push
+	assert.Equal(t, []string{
+		`Jobs["build"].Extra["snapshot"]`,
+		`Jobs["build"].Steps[0].Extra["retries"]`,
+	}, w.UnknownFields())
+
+	rendered, err := w.Render()
+	require.NoError(t, err)
+	assert.Equal(t, extraTestDocument, string(rendered))
+}
+
+func TestParseWorkflowStrict(t *testing.T) {
+	type testCase struct {
+		name     string
+		doc      string
+		expected string
+	}
+
+	testCases := []testCase{
+		{
+			name: "keys of the schema gocto has no field for",
+			doc: `name: CI
+on:
+  release:
+    types: [published]
+  issue_comment:
+    types: [created]
+  pull_request:
+    types: [opened, synchronize]
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make
+`,
+		},
+		{
+			name:     "unknown keys",
+			doc:      extraTestDocument,
+			expected: "Jobs[\"build\"].Extra[\"snapshot\"]: unknown key\nJobs[\"build\"].Steps[0].Extra[\"retries\"]: unknown key",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseWorkflowStrict([]byte(tc.doc))
+			if tc.expected == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tc.expected)
+		})
+	}
+}
+
+func TestMarshalExtra(t *testing.T) {
+	type testCase struct {
+		name     string
+		step     Step
+		expected string
+	}
+
+	testCases := []testCase{
+		{
+			name:     "after the fields",
+			step:     Step{Run: "make", Extra: map[string]any{"retries": 3, "cache": true}},
+			expected: `{"run":"make","cache":true,"retries":3}`,
+		},
+		{
+			name:     "no fields",
+			step:     Step{Extra: map[string]any{"retries": 3}},
+			expected: `{"retries":3}`,
+		},
+		{
+			name:     "fields take precedence",
+			step:     Step{Run: "make", Extra: map[string]any{"run": "make test"}},
+			expected: `{"run":"make"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := json.Marshal(tc.step)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(b))
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package gocto
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/cakehappens/gocto/internal/glob"
+)
+
+// Filter patterns
+// https://docs.github.com/en/actions/reference/workflow-syntax-for-github-actions#filter-pattern-cheat-sheet
+//
+// Patterns are matched the way GitHub does, in order with the last matching pattern deciding,
+// so a leading ! excludes what earlier patterns included. Invalid patterns never match,
+// Validate reports them.
+
+// MatchesBranch reports whether branch passes the branch filters, a nil filter matches every branch
+func (f *OnBranches) MatchesBranch(branch string) bool {
+	if f == nil {
+		return true
+	}
+
+	return matchFilter(f.Branches, f.BranchesIgnore, strings.TrimPrefix(branch, "refs/heads/"))
+}
+
+func (f *OnBranches) filtersBranches() bool {
+	return f != nil && (len(f.Branches) > 0 || len(f.BranchesIgnore) > 0)
+}
+
+// MatchesTag reports whether tag passes the tag filters, a nil filter matches every tag
+func (f *OnTags) MatchesTag(tag string) bool {
+	if f == nil {
+		return true
+	}
+
+	return matchFilter(f.Tags, f.TagsIgnore, strings.TrimPrefix(tag, "refs/tags/"))
+}
+
+func (f *OnTags) filtersTags() bool {
+	return f != nil && (len(f.Tags) > 0 || len(f.TagsIgnore) > 0)
+}
+
+// MatchesPaths reports whether the changed files pass the path filters: with paths at least one
+// changed file has to match, with paths-ignore at least one changed file must not be ignored.
+// A nil filter matches any change.
+func (f *OnPaths) MatchesPaths(changed []string) bool {
+	if f == nil {
+		return true
+	}
+
+	if len(f.Paths) > 0 {
+		for _, p := range changed {
+			if ok, err := glob.MatchList(f.Paths, p); ok && err == nil {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(f.PathsIgnore) > 0 && len(changed) > 0 {
+		for _, p := range changed {
+			if ok, err := glob.MatchAny(f.PathsIgnore, p); !ok && err == nil {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+// Matches reports whether a push of ref changing the given files triggers the workflow.
+// When only branches or only tags are filtered, pushes of the other kind of ref don't trigger,
+// and path filters are not evaluated for tags.
+func (on *OnPush) Matches(ref string, changed []string) bool {
+	if on == nil {
+		return false
+	}
+
+	switch {
+	case strings.HasPrefix(ref, "refs/tags/"):
+		if !on.OnTags.filtersTags() {
+			return !on.OnBranches.filtersBranches()
+		}
+		return on.OnTags.MatchesTag(ref)
+	case strings.HasPrefix(ref, "refs/heads/"):
+		if on.OnBranches.filtersBranches() {
+			if !on.OnBranches.MatchesBranch(ref) {
+				return false
+			}
+		} else if on.OnTags.filtersTags() {
+			return false
+		}
+		return on.OnPaths.MatchesPaths(changed)
+	}
+
+	return false
+}
+
+// Matches reports whether a pull request into baseRef changing the given files triggers the workflow
+func (on *OnPullRequest) Matches(baseRef string, changed []string) bool {
+	if on == nil {
+		return false
+	}
+
+	return on.OnBranches.MatchesBranch(baseRef) && on.OnPaths.MatchesPaths(changed)
+}
+
+// Matches reports whether a run of the named workflow on branch triggers the workflow
+func (on *OnWorkflowRun) Matches(workflow, branch string) bool {
+	if on == nil {
+		return false
+	}
+
+	for _, w := range on.Workflows {
+		if w == workflow {
+			return on.OnBranches.MatchesBranch(branch)
+		}
+	}
+
+	return false
+}
+
+// FilterError is an invalid branch, tag or path filter
+type FilterError struct {
+	Path    string
+	Message string
+}
+
+func (e *FilterError) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+// validateFilters checks what GitHub rejects but the schema can't express clearly:
+// a filter and its ignore list can't both be set, and patterns have to compile
+func (on WorkflowOn) validateFilters() []*FilterError {
+	var errs []*FilterError
+	on.eachFilter(func(path, name string, include, ignore []string) {
+		if len(include) > 0 && len(ignore) > 0 {
+			errs = append(errs, &FilterError{
+				Path:    path,
+				Message: fmt.Sprintf("%s and %s-ignore can't both be set, use %s with ! patterns instead", name, name, name),
+			})
+		}
+
+		for _, pattern := range slices.Concat(include, ignore) {
+			if _, err := glob.Compile(pattern); err != nil {
+				errs = append(errs, &FilterError{Path: path, Message: err.Error()})
+			}
+		}
+	})
+
+	return errs
+}
+
+// eachFilter calls fn with every branch, tag and path filter of the triggers, e.g. "On.Push", "branches"
+func (on WorkflowOn) eachFilter(fn func(path, name string, include, ignore []string)) {
+	branches := func(path string, f *OnBranches) {
+		if f != nil {
+			fn(path, "branches", f.Branches, f.BranchesIgnore)
+		}
+	}
+
+	paths := func(path string, f *OnPaths) {
+		if f != nil {
+			fn(path, "paths", f.Paths, f.PathsIgnore)
+		}
+	}
+
+	if on.Push != nil {
+		branches("On.Push", on.Push.OnBranches)
+		paths("On.Push", on.Push.OnPaths)
+		if on.Push.OnTags != nil {
+			fn("On.Push", "tags", on.Push.Tags, on.Push.TagsIgnore)
+		}
+	}
+
+	if on.PullRequest != nil {
+		branches("On.PullRequest", on.PullRequest.OnBranches)
+		paths("On.PullRequest", on.PullRequest.OnPaths)
+	}
+
+	if on.PullRequestTarget != nil {
+		branches("On.PullRequestTarget", on.PullRequestTarget.OnBranches)
+		paths("On.PullRequestTarget", on.PullRequestTarget.OnPaths)
+	}
+
+	if on.Run != nil {
+		branches("On.Run", on.Run.OnBranches)
+	}
+}
+
+// checkNegatedIgnorePattern warns about ! patterns in ignore lists, GitHub only documents them for the include lists
+func checkNegatedIgnorePattern(w Workflow) []Diagnostic {
+	var diags []Diagnostic
+	w.On.eachFilter(func(path, name string, _, ignore []string) {
+		for i, pattern := range ignore {
+			if p, err := glob.Compile(pattern); err != nil || !p.Negated {
+				continue
+			}
+
+			diags = append(diags, Diagnostic{
+				Path:    fmt.Sprintf("%s.%sIgnore[%d]", path, strings.ToUpper(name[:1])+name[1:], i),
+				Message: fmt.Sprintf("%s-ignore pattern %q is negated, use %s with ! patterns instead", name, pattern, name),
+			})
+		}
+	})
+
+	return diags
+}
+
+func matchFilter(include, ignore []string, s string) bool {
+	if len(include) > 0 {
+		if ok, err := glob.MatchList(include, s); err != nil || !ok {
+			return false
+		}
+	}
+
+	if len(ignore) > 0 {
+		if ok, err := glob.MatchAny(ignore, s); err != nil || ok {
+			return false
+		}
+	}
+
+	return true
+}
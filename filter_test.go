@@ -0,0 +1,168 @@
+package gocto
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnPushMatches(t *testing.T) {
+	type testCase struct {
+		name    string
+		on      *OnPush
+		ref     string
+		changed []string
+		want    bool
+	}
+
+	testCases := []testCase{
+		{
+			name: "no filters",
+			on:   &OnPush{},
+			ref:  "refs/tags/v1",
+			want: true,
+		},
+		{
+			name: "branch matches",
+			on:   &OnPush{OnBranches: &OnBranches{Branches: []string{"main", "releases/**"}}},
+			ref:  "refs/heads/releases/v1/rc",
+			want: true,
+		},
+		{
+			name: "later negation excludes",
+			on:   &OnPush{OnBranches: &OnBranches{Branches: []string{"releases/**", "!releases/**-alpha"}}},
+			ref:  "refs/heads/releases/v1-alpha",
+		},
+		{
+			name: "negation is overridden by a later pattern",
+			on:   &OnPush{OnBranches: &OnBranches{Branches: []string{"releases/**", "!releases/**-alpha", "releases/v2-alpha"}}},
+			ref:  "refs/heads/releases/v2-alpha",
+			want: true,
+		},
+		{
+			name: "ignored branch",
+			on:   &OnPush{OnBranches: &OnBranches{BranchesIgnore: []string{"dependabot/**"}}},
+			ref:  "refs/heads/dependabot/go/x",
+		},
+		{
+			name: "only branches filtered, tag push",
+			on:   &OnPush{OnBranches: &OnBranches{Branches: []string{"main"}}},
+			ref:  "refs/tags/v1",
+		},
+		{
+			name: "only tags filtered, branch push",
+			on:   &OnPush{OnTags: &OnTags{Tags: []string{"v[0-9]+.*"}}},
+			ref:  "refs/heads/main",
+		},
+		{
+			name: "tag matches character class with +",
+			on:   &OnPush{OnTags: &OnTags{Tags: []string{"v[0-9]+.*"}}},
+			ref:  "refs/tags/v12.0",
+			want: true,
+		},
+		{
+			name:    "paths are not evaluated for tags",
+			on:      &OnPush{OnPaths: &OnPaths{Paths: []string{"docs/**"}}},
+			ref:     "refs/tags/v1",
+			changed: []string{"main.go"},
+			want:    true,
+		},
+		{
+			name:    "path matches",
+			on:      &OnPush{OnPaths: &OnPaths{Paths: []string{"**.go", "!vendor/**"}}},
+			ref:     "refs/heads/main",
+			changed: []string{"vendor/x.go", "cmd/main.go"},
+			want:    true,
+		},
+		{
+			name:    "every path excluded",
+			on:      &OnPush{OnPaths: &OnPaths{Paths: []string{"**.go", "!vendor/**"}}},
+			ref:     "refs/heads/main",
+			changed: []string{"vendor/x.go", "README.md"},
+		},
+		{
+			name:    "every path ignored",
+			on:      &OnPush{OnPaths: &OnPaths{PathsIgnore: []string{"docs/**", "*.md"}}},
+			ref:     "refs/heads/main",
+			changed: []string{"docs/a/b.txt", "README.md"},
+		},
+		{
+			name:    "one path not ignored",
+			on:      &OnPush{OnPaths: &OnPaths{PathsIgnore: []string{"docs/**", "*.md"}}},
+			ref:     "refs/heads/main",
+			changed: []string{"docs/a/b.txt", "main.go"},
+			want:    true,
+		},
+		{
+			name: "no push trigger",
+			ref:  "refs/heads/main",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.on.Matches(tc.ref, tc.changed))
+		})
+	}
+}
+
+func TestOnPullRequestMatches(t *testing.T) {
+	on := &OnPullRequest{
+		OnBranches: &OnBranches{Branches: []string{"main", "feature-?"}},
+		OnPaths:    &OnPaths{Paths: []string{"src/*"}},
+	}
+
+	assert.True(t, on.Matches("main", []string{"src/a.go"}))
+	assert.True(t, on.Matches("refs/heads/feature-", []string{"src/a.go"}))
+	assert.False(t, on.Matches("main", []string{"src/a/b.go"}))
+	assert.False(t, on.Matches("develop", []string{"src/a.go"}))
+}
+
+func TestValidateFilters(t *testing.T) {
+	w := Workflow{
+		Name: "filters",
+		On: WorkflowOn{
+			Push: &OnPush{
+				OnBranches: &OnBranches{Branches: []string{"main"}, BranchesIgnore: []string{"dev"}},
+			},
+			PullRequest: &OnPullRequest{
+				OnPaths: &OnPaths{PathsIgnore: []string{"!docs/**", "[a"}},
+			},
+		},
+		Jobs: map[string]Job{
+			"build": {
+				RunsOn: StringOrSlice{"ubuntu-latest"},
+				Steps:  []Step{{Run: "make"}},
+			},
+		},
+	}
+
+	err := w.Validate()
+	require.Error(t, err)
+
+	var messages []string
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var filterErr *FilterError
+		require.True(t, errors.As(e, &filterErr), e.Error())
+		messages = append(messages, filterErr.Error())
+	}
+
+	assert.Equal(t, []string{
+		"On.Push: branches and branches-ignore can't both be set, use branches with ! patterns instead",
+		`On.PullRequest: pattern "[a" has an unclosed [`,
+	}, messages)
+
+	var warnings []Diagnostic
+	for _, d := range Lint(w) {
+		if d.Rule == "negated-ignore-pattern" {
+			warnings = append(warnings, Diagnostic{Path: d.Path, Severity: d.Severity, Message: d.Message})
+		}
+	}
+	assert.Equal(t, []Diagnostic{{
+		Path:     "On.PullRequest.PathsIgnore[0]",
+		Severity: SeverityWarning,
+		Message:  `paths-ignore pattern "!docs/**" is negated, use paths with ! patterns instead`,
+	}}, warnings)
+}
@@ -0,0 +1,139 @@
+// Package glob implements the filter pattern syntax of workflow triggers
+// https://docs.github.com/en/actions/reference/workflow-syntax-for-github-actions#filter-pattern-cheat-sheet
+package glob
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Pattern is a single compiled filter pattern
+type Pattern struct {
+	Raw string
+	// Negated patterns start with !, they exclude what earlier patterns in a list matched
+	Negated bool
+	re      *regexp.Regexp
+}
+
+// Compile turns a filter pattern into a regular expression.
+// A single star matches zero or more characters except /, a double star matches any characters
+// and **/ matches zero or more directories. ? and + match zero or one, and one or more,
+// of the preceding character. [] matches one character listed or in a range, e.g. [0-9a-z],
+// and \ escapes the next character.
+func Compile(pattern string) (Pattern, error) {
+	p := Pattern{Raw: pattern}
+	if strings.HasPrefix(pattern, "!") {
+		p.Negated = true
+		pattern = pattern[1:]
+	}
+
+	// atoms are kept separate so ? and + apply to the whole preceding atom
+	var atoms []string
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch c {
+		case '\\':
+			if i+1 >= len(pattern) {
+				return Pattern{}, fmt.Errorf("pattern %q ends with an escape", p.Raw)
+			}
+			i++
+			atoms = append(atoms, regexp.QuoteMeta(pattern[i:i+1]))
+		case '*':
+			switch {
+			case strings.HasPrefix(pattern[i:], "**/"):
+				// **/ also matches no directories at all, so **/README.md matches README.md
+				atoms = append(atoms, "(?:.*/)?")
+				i += 2
+			case strings.HasPrefix(pattern[i:], "**"):
+				atoms = append(atoms, ".*")
+				i++
+			default:
+				atoms = append(atoms, "[^/]*")
+			}
+		case '?', '+':
+			if len(atoms) == 0 {
+				return Pattern{}, fmt.Errorf("pattern %q starts with %c, which needs a preceding character", p.Raw, c)
+			}
+			last := len(atoms) - 1
+			atoms[last] = "(?:" + atoms[last] + ")" + string(c)
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				return Pattern{}, fmt.Errorf("pattern %q has an unclosed [", p.Raw)
+			}
+			class := pattern[i+1 : i+1+end]
+			if class == "" {
+				return Pattern{}, fmt.Errorf("pattern %q has an empty []", p.Raw)
+			}
+			atoms = append(atoms, "["+strings.NewReplacer(`\`, `\\`, "[", `\[`, "^", `\^`).Replace(class)+"]")
+			i += end + 1
+		default:
+			atoms = append(atoms, regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	re, err := regexp.Compile("^" + strings.Join(atoms, "") + "$")
+	if err != nil {
+		return Pattern{}, fmt.Errorf("pattern %q: %w", p.Raw, err)
+	}
+
+	p.re = re
+	return p, nil
+}
+
+// Match reports whether s matches the pattern, ignoring negation
+func (p Pattern) Match(s string) bool {
+	return p.re.MatchString(s)
+}
+
+func compileAll(patterns []string) ([]Pattern, error) {
+	compiled := make([]Pattern, 0, len(patterns))
+	for _, raw := range patterns {
+		p, err := Compile(raw)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, p)
+	}
+
+	return compiled, nil
+}
+
+// MatchList applies patterns in order, the last pattern which matches decides:
+// s is included when it is a positive pattern, and excluded when it is negated
+func MatchList(patterns []string, s string) (bool, error) {
+	compiled, err := compileAll(patterns)
+	if err != nil {
+		return false, err
+	}
+
+	matched := false
+	for _, p := range compiled {
+		if p.Match(s) {
+			matched = !p.Negated
+		}
+	}
+
+	return matched, nil
+}
+
+// MatchAny reports whether any pattern matches s, it is used for *-ignore lists which don't support negation
+func MatchAny(patterns []string, s string) (bool, error) {
+	compiled, err := compileAll(patterns)
+	if err != nil {
+		return false, err
+	}
+
+	for _, p := range compiled {
+		if p.Negated {
+			return false, fmt.Errorf("pattern %q: ignore lists can't contain negated patterns", p.Raw)
+		}
+
+		if p.Match(s) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,70 @@
+package glob
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cases from the filter pattern cheat sheet
+func TestCompile(t *testing.T) {
+	type testCase struct {
+		pattern string
+		matches []string
+		misses  []string
+	}
+
+	cases := []testCase{
+		{pattern: "feature/*", matches: []string{"feature/my-branch", "feature/your-branch"}, misses: []string{"feature/a/b"}},
+		{pattern: "feature/**", matches: []string{"feature/beta-a/my-branch", "feature/mona/the/octocat"}},
+		{pattern: "main", matches: []string{"main"}, misses: []string{"main2", "releases/main"}},
+		{pattern: "v2*", matches: []string{"v2", "v2.0", "v2.9"}, misses: []string{"v1"}},
+		{pattern: "v[12].[0-9]+.[0-9]+", matches: []string{"v1.10.1", "v2.0.0"}, misses: []string{"v3.0.0", "v1.a.0"}},
+		{pattern: "*.jsx?", matches: []string{"page.js", "page.jsx"}, misses: []string{"page.jsxx", "app/page.js"}},
+		{pattern: "**.js", matches: []string{"index.js", "js/index.js", "src/js/app.js"}},
+		{pattern: "docs/**", matches: []string{"docs/README.md", "docs/file.txt"}, misses: []string{"README.md"}},
+		{pattern: "**/docs/**", matches: []string{"docs/hello.md", "dir/docs/my-file.txt", "space/docs/plan/space.doc"}},
+		{pattern: "**/README.md", matches: []string{"README.md", "js/README.md"}},
+		{pattern: "**/*src/**", matches: []string{"a/src/app.js", "my-src/code/js/app.js"}},
+		{pattern: "**/*-post.md", matches: []string{"my-post.md", "path/their-post.md"}},
+		{pattern: "*", matches: []string{"main", "releases"}, misses: []string{"releases/v1"}},
+		{pattern: `\*literal`, matches: []string{"*literal"}, misses: []string{"xliteral"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.pattern, func(t *testing.T) {
+			p, err := Compile(tc.pattern)
+			require.NoError(t, err)
+
+			for _, s := range tc.matches {
+				assert.True(t, p.Match(s), s)
+			}
+			for _, s := range tc.misses {
+				assert.False(t, p.Match(s), s)
+			}
+		})
+	}
+}
+
+func TestMatchList(t *testing.T) {
+	patterns := []string{"releases/**", "!releases/**-alpha", "releases/keep-alpha"}
+
+	for s, expected := range map[string]bool{
+		"releases/10":         true,
+		"releases/beta/mona":  true,
+		"releases/10-alpha":   false,
+		"releases/keep-alpha": true,
+		"main":                false,
+	} {
+		ok, err := MatchList(patterns, s)
+		require.NoError(t, err)
+		assert.Equal(t, expected, ok, s)
+	}
+
+	_, err := MatchAny([]string{"!main"}, "main")
+	assert.Error(t, err)
+
+	_, err = Compile("[a-")
+	assert.Error(t, err)
+}
@@ -1,7 +1,56 @@
 package util
 
+import (
+	"bytes"
+	"encoding/json"
+)
+
 const JSONNull string = "null"
 
 func IsJSONNull(data []byte) bool {
 	return string(data) == JSONNull
 }
+
+// MapMembers calls fn with every member of the JSON object data, in order, and returns the object of the values fn returns.
+// Anything but an object is returned as is.
+func MapMembers(data []byte, fn func(key string, value json.RawMessage) (json.RawMessage, error)) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return data, nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+
+		key := tok.(string)
+		if value, err = fn(key, value); err != nil {
+			return nil, err
+		}
+
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+
+		name, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,9 @@
+package util
+
+// OrEmpty returns m, or an empty map when m is nil
+func OrEmpty[M ~map[K]V, K comparable, V any](m M) M {
+	if m == nil {
+		return M{}
+	}
+	return m
+}
@@ -0,0 +1,40 @@
+package util
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	bashUnquoted = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+	// in PowerShell an unquoted a,b is an array and a leading @ splats a variable
+	pwshUnquoted = regexp.MustCompile(`^[A-Za-z0-9_%+=:./-]+$`)
+)
+
+// BashUnquoted reports whether word is safe to leave unquoted in bash or sh
+func BashUnquoted(word string) bool {
+	return bashUnquoted.MatchString(word)
+}
+
+// PwshUnquoted reports whether word is safe to leave unquoted in PowerShell
+func PwshUnquoted(word string) bool {
+	return pwshUnquoted.MatchString(word)
+}
+
+// BashQuote quotes a word for bash or sh, leaving the common words like ./cmd/release as they are
+func BashQuote(word string) string {
+	if BashUnquoted(word) {
+		return word
+	}
+
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
+
+// PwshQuote quotes a word for PowerShell, leaving the common words like ./cmd/release as they are
+func PwshQuote(word string) string {
+	if PwshUnquoted(word) {
+		return word
+	}
+
+	return "'" + strings.ReplaceAll(word, "'", "''") + "'"
+}
@@ -0,0 +1,149 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// JSONToYAMLNode converts a JSON document into a YAML node tree, preserving key order.
+// Styles are reset so the encoder picks plain scalars and block collections,
+// multi-line strings become literal blocks.
+func JSONToYAMLNode(data []byte) (*yaml.Node, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+
+	resetYAMLStyle(&node)
+	return &node, nil
+}
+
+func resetYAMLStyle(node *yaml.Node) {
+	node.Style = 0
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!str" && strings.Contains(node.Value, "\n") {
+		node.Style = yaml.LiteralStyle
+	}
+
+	for _, c := range node.Content {
+		resetYAMLStyle(c)
+	}
+}
+
+// EncodeYAML encodes a node tree with the two space indentation GitHub uses in its docs
+func EncodeYAML(node *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(node); err != nil {
+		return nil, err
+	}
+
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// WalkYAMLMappings calls fn for every key/value pair of every mapping in the tree
+func WalkYAMLMappings(node *yaml.Node, fn func(key, value *yaml.Node)) {
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			fn(node.Content[i], node.Content[i+1])
+		}
+	}
+
+	for _, c := range node.Content {
+		WalkYAMLMappings(c, fn)
+	}
+}
+
+// YAMLToJSON converts a YAML document into JSON, preserving key order.
+// Scalars keep their YAML type, aliases are expanded.
+func YAMLToJSON(data []byte) ([]byte, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, &node); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func writeJSON(buf *bytes.Buffer, node *yaml.Node) error {
+	switch node.Kind {
+	case 0:
+		buf.WriteString(JSONNull)
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			buf.WriteString(JSONNull)
+			return nil
+		}
+		return writeJSON(buf, node.Content[0])
+	case yaml.AliasNode:
+		return writeJSON(buf, node.Alias)
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, c := range node.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeJSON(buf, c); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case yaml.MappingNode:
+		buf.WriteByte('{')
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, err := json.Marshal(node.Content[i].Value)
+			if err != nil {
+				return err
+			}
+			buf.Write(key)
+			buf.WriteByte(':')
+			if err := writeJSON(buf, node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case yaml.ScalarNode:
+		var v any = node.Value
+		switch node.ShortTag() {
+		case "!!int", "!!float":
+			// numbers which are valid JSON are kept as written, e.g. 1.10
+			var n json.Number
+			if err := json.Unmarshal([]byte(node.Value), &n); err == nil {
+				buf.WriteString(node.Value)
+				return nil
+			}
+			if err := node.Decode(&v); err != nil {
+				return err
+			}
+		case "!!null", "!!bool":
+			if err := node.Decode(&v); err != nil {
+				return err
+			}
+		}
+
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", node.Line, err)
+		}
+		buf.Write(b)
+	}
+
+	return nil
+}
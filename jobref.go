@@ -0,0 +1,163 @@
+package gocto
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+
+	"github.com/cakehappens/gocto/expressions"
+)
+
+// JobRef is a handle to a job added with Workflow.AddJob. Needs and needs.<id> expressions built from it
+// carry the ID the job was added with, so the ID is only written down once.
+type JobRef struct {
+	id string
+}
+
+func (r JobRef) ID() string {
+	return r.id
+}
+
+// Output is needs.<id>.outputs.<name>, only jobs needing the job can use it
+func (r JobRef) Output(name string) expressions.Expression {
+	return expressions.NeedsOutput(r.id, name)
+}
+
+// Result is needs.<id>.result, one of success, failure, cancelled or skipped
+func (r JobRef) Result() expressions.Expression {
+	return expressions.NeedsResult(r.id)
+}
+
+// Needs returns the IDs of the referenced jobs, for Job.Needs
+func Needs(refs ...JobRef) StringOrSlice {
+	var ids StringOrSlice
+	for _, r := range refs {
+		ids = append(ids, r.id)
+	}
+
+	return ids
+}
+
+// AddJob adds or replaces the job with the given ID, and returns a handle for the jobs needing it
+func (w *Workflow) AddJob(id string, job Job) JobRef {
+	*w = w.Job(id, job)
+	return JobRef{id: id}
+}
+
+// Ref returns a handle to an existing job, e.g. of a workflow which was read from a file
+func (w Workflow) Ref(id string) (JobRef, bool) {
+	_, ok := w.Jobs[id]
+	return JobRef{id: id}, ok
+}
+
+// After adds the referenced jobs to Needs
+func (j Job) After(refs ...JobRef) Job {
+	return j.WithNeeds(Needs(refs...)...)
+}
+
+var needsContextPattern = regexp.MustCompile(`(?:^|[^\w.-])needs\.([A-Za-z_][\w-]*)(?:\.outputs\.([A-Za-z_][\w-]*))?`)
+
+type needsReference struct {
+	jobID  string
+	output string
+}
+
+// needsReferences returns the jobs and outputs referenced through the needs context
+func needsReferences(val string, bare bool) []needsReference {
+	exprs := []string{val}
+	if !bare {
+		exprs = expressionsIn(val)
+	}
+
+	var refs []needsReference
+	for _, expr := range exprs {
+		for _, m := range needsContextPattern.FindAllStringSubmatch(expr, -1) {
+			refs = append(refs, needsReference{jobID: m[1], output: m[2]})
+		}
+	}
+
+	return refs
+}
+
+// jobStrings returns every string field of a job which may contain expressions, keyed by field path
+func jobStrings(job Job) map[string]string {
+	vals := map[string]string{
+		"Name": job.Name,
+	}
+
+	for k, v := range job.Env {
+		vals[fmt.Sprintf("Env[%q]", k)] = v
+	}
+
+	for k, v := range job.Outputs {
+		vals[fmt.Sprintf("Outputs[%q]", k)] = v
+	}
+
+	for k, v := range job.With {
+		vals[fmt.Sprintf("With[%q]", k)] = v
+	}
+
+	return vals
+}
+
+// checkNeedsReference checks Needs and needs.<id> references, which break when a job is renamed.
+// The needs context only holds the jobs a job directly needs.
+func checkNeedsReference(w Workflow) []Diagnostic {
+	var diags []Diagnostic
+	for _, jobID := range sortedJobIDs(w) {
+		job := w.Jobs[jobID]
+
+		for _, need := range job.Needs {
+			if _, ok := w.Jobs[need]; !ok {
+				diags = append(diags, Diagnostic{
+					Path:    jobPath(jobID) + ".Needs",
+					Message: fmt.Sprintf("needs job %q, which does not exist", need),
+				})
+			}
+		}
+
+		check := func(path string, refs []needsReference) {
+			for _, ref := range refs {
+				needed, ok := w.Jobs[ref.jobID]
+				switch {
+				case !slices.Contains(job.Needs, ref.jobID):
+					diags = append(diags, Diagnostic{
+						Path:    path,
+						Message: fmt.Sprintf("needs.%s is not available, job %q does not need it", ref.jobID, jobID),
+					})
+				case !ok:
+					// reported for Needs
+				case ref.output != "" && needed.Uses == "" && !hasOutput(needed, ref.output):
+					diags = append(diags, Diagnostic{
+						Path:    path,
+						Message: fmt.Sprintf("needs.%s.outputs.%s is not an output of job %q", ref.jobID, ref.output, ref.jobID),
+					})
+				}
+			}
+		}
+
+		check(jobPath(jobID)+".If", needsReferences(job.If, true))
+
+		fields := jobStrings(job)
+		for _, field := range slices.Sorted(maps.Keys(fields)) {
+			check(jobPath(jobID)+"."+field, needsReferences(fields[field], false))
+		}
+
+		for i, s := range job.Steps {
+			check(stepPath(jobID, i)+".If", needsReferences(s.If, true))
+
+			fields := stepStrings(s)
+			for _, field := range slices.Sorted(maps.Keys(fields)) {
+				check(stepPath(jobID, i)+"."+field, needsReferences(fields[field], false))
+			}
+		}
+	}
+
+	return diags
+}
+
+func hasOutput(job Job, name string) bool {
+	_, ok := job.Outputs[name]
+	return ok
+}
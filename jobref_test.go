@@ -0,0 +1,80 @@
+package gocto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cakehappens/gocto/expressions"
+)
+
+func TestJobRef(t *testing.T) {
+	w := NewWorkflow("release")
+	build := w.AddJob("build", NewJob("ubuntu-latest").WithOutput("version", expressions.StepOutput("version", "value")))
+	w.AddJob("publish", NewJob("ubuntu-latest").
+		After(build).
+		WithIf(build.Result().And("github.ref == 'refs/heads/main'")).
+		WithSteps(Step{Run: "make publish VERSION=" + build.Output("version").String()}))
+
+	assert.Equal(t, StringOrSlice{"build"}, w.Jobs["publish"].Needs)
+	assert.Equal(t, "( needs.build.result && github.ref == 'refs/heads/main' )", w.Jobs["publish"].If)
+	assert.Equal(t, "make publish VERSION=${{needs.build.outputs.version}}", w.Jobs["publish"].Steps[0].Run)
+	assert.Equal(t, StringOrSlice{"build", "publish"}, Needs(build, JobRef{id: "publish"}))
+
+	ref, ok := w.Ref("build")
+	assert.True(t, ok)
+	assert.Equal(t, build, ref)
+	_, ok = w.Ref("test")
+	assert.False(t, ok)
+
+	for _, d := range Lint(w) {
+		assert.NotEqual(t, "needs-reference", d.Rule, d.String())
+	}
+}
+
+func TestLintNeedsReference(t *testing.T) {
+	w := Workflow{
+		Name: "needs",
+		Jobs: map[string]Job{
+			"build": {
+				Outputs: map[string]string{"version": "${{ steps.version.outputs.value }}"},
+			},
+			"call": {
+				Uses: "./.github/workflows/called.yaml",
+			},
+			"deploy": {
+				Needs: StringOrSlice{"build", "call", "compile"},
+				If:    "needs.build.result == 'success' && needs.test.result == 'success'",
+				Env:   map[string]string{"TAG": "${{ needs.call.outputs.tag }}"},
+				Steps: []Step{
+					{Run: "deploy ${{ needs.build.outputs.version }} ${{ needs.build.outputs.sha }}"},
+				},
+			},
+		},
+	}
+
+	var got []Diagnostic
+	for _, d := range Lint(w) {
+		if d.Rule == "needs-reference" {
+			got = append(got, Diagnostic{Rule: d.Rule, Path: d.Path, Message: d.Message})
+		}
+	}
+
+	assert.ElementsMatch(t, []Diagnostic{
+		{
+			Rule:    "needs-reference",
+			Path:    `Jobs["deploy"].Needs`,
+			Message: `needs job "compile", which does not exist`,
+		},
+		{
+			Rule:    "needs-reference",
+			Path:    `Jobs["deploy"].If`,
+			Message: `needs.test is not available, job "deploy" does not need it`,
+		},
+		{
+			Rule:    "needs-reference",
+			Path:    `Jobs["deploy"].Steps[0].Run`,
+			Message: `needs.build.outputs.sha is not an output of job "build"`,
+		},
+	}, got)
+}
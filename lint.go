@@ -0,0 +1,545 @@
+package gocto
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return fmt.Sprintf("severity(%d)", int(s))
+	}
+}
+
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Severity) UnmarshalText(data []byte) error {
+	for _, candidate := range []Severity{SeverityInfo, SeverityWarning, SeverityError} {
+		if candidate.String() == string(data) {
+			*s = candidate
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown severity %q", data)
+}
+
+// Diagnostic is a single problem found by a lint Rule
+type Diagnostic struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	// Workflow is the filename of the workflow the problem was found in
+	Workflow string `json:"workflow"`
+	// Path is the Go field path of the offending value, e.g. Jobs["build"].Steps[3]
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s: %s: %s [%s]", d.Workflow, d.Path, d.Severity, d.Message, d.Rule)
+}
+
+// Rule is a single lint check, only one of Check and CheckAll should be set.
+// Rules only need to set Path and Message on the diagnostics they return,
+// CheckAll rules must also set Workflow.
+type Rule struct {
+	Name        string
+	Description string
+	Severity    Severity
+	// Check is called once for every workflow
+	Check func(w Workflow) []Diagnostic
+	// CheckAll is called once with every workflow, for problems which span workflows
+	CheckAll func(ws []Workflow) []Diagnostic
+	// done is called at the end of every LintAll, e.g. to drop what the rule cached for the call
+	done func()
+}
+
+// Linter runs a set of rules, each of which can be disabled or have its severity changed
+type Linter struct {
+	rules    []Rule
+	disabled map[string]bool
+	severity map[string]Severity
+}
+
+// NewLinter returns a linter running DefaultRules and the given rules
+func NewLinter(rules ...Rule) *Linter {
+	return &Linter{
+		rules:    append(DefaultRules(), rules...),
+		disabled: make(map[string]bool),
+		severity: make(map[string]Severity),
+	}
+}
+
+func (l *Linter) AddRule(r Rule) *Linter {
+	l.rules = append(l.rules, r)
+	return l
+}
+
+func (l *Linter) Disable(names ...string) *Linter {
+	for _, name := range names {
+		l.disabled[name] = true
+	}
+	return l
+}
+
+func (l *Linter) Enable(names ...string) *Linter {
+	for _, name := range names {
+		delete(l.disabled, name)
+	}
+	return l
+}
+
+func (l *Linter) SetSeverity(name string, s Severity) *Linter {
+	l.severity[name] = s
+	return l
+}
+
+// Rules returns the enabled rules, with their effective severity
+func (l *Linter) Rules() []Rule {
+	var rules []Rule
+	for _, r := range l.rules {
+		if l.disabled[r.Name] {
+			continue
+		}
+
+		if s, ok := l.severity[r.Name]; ok {
+			r.Severity = s
+		}
+
+		rules = append(rules, r)
+	}
+
+	return rules
+}
+
+func (l *Linter) Lint(w Workflow) []Diagnostic {
+	return l.LintAll(w)
+}
+
+// LintAll runs every enabled rule, CheckAll rules see all the workflows at once
+func (l *Linter) LintAll(ws ...Workflow) []Diagnostic {
+	var diags []Diagnostic
+	for _, r := range l.Rules() {
+		if r.done != nil {
+			defer r.done()
+		}
+
+		var found []Diagnostic
+		if r.Check != nil {
+			for _, w := range ws {
+				for _, d := range r.Check(w) {
+					d.Workflow = w.GetFilename()
+					found = append(found, d)
+				}
+			}
+		}
+
+		if r.CheckAll != nil {
+			found = append(found, r.CheckAll(ws)...)
+		}
+
+		for _, d := range found {
+			d.Rule = r.Name
+			d.Severity = r.Severity
+			diags = append(diags, d)
+		}
+	}
+
+	return diags
+}
+
+// Lint runs DefaultRules against a single workflow
+func Lint(w Workflow) []Diagnostic {
+	return NewLinter().Lint(w)
+}
+
+func DefaultRules() []Rule {
+	scripts := &scriptParser{}
+	return []Rule{
+		{
+			Name:        "duplicate-step-id",
+			Description: "step IDs must be unique within a job",
+			Severity:    SeverityError,
+			Check:       checkDuplicateStepID,
+		},
+		{
+			Name:        "step-output-reference",
+			Description: "steps.<id> references must name an earlier step of the same job",
+			Severity:    SeverityError,
+			Check:       checkStepOutputReference,
+		},
+		{
+			Name:        "needs-reference",
+			Description: "needs and needs.<id> references must name a job, which the job needs, and its outputs",
+			Severity:    SeverityError,
+			Check:       checkNeedsReference,
+		},
+		{
+			Name:        "step-uses-and-run",
+			Description: "a step runs either an action or a script, not both",
+			Severity:    SeverityError,
+			Check:       checkStepUsesAndRun,
+		},
+		{
+			Name:        "job-uses-and-steps",
+			Description: "a job calls either a reusable workflow or runs steps, not both",
+			Severity:    SeverityError,
+			Check:       checkJobUsesAndSteps,
+		},
+		{
+			Name:        "shell-on-uses",
+			Description: "shell only applies to run steps",
+			Severity:    SeverityError,
+			Check:       checkShellOnUses,
+		},
+		{
+			Name:        "shell-template",
+			Description: "custom shells must have a {0} placeholder for the script",
+			Severity:    SeverityError,
+			Check:       checkShellTemplate,
+		},
+		{
+			Name:        "windows-shell",
+			Description: "powershell and cmd are only available on Windows runners",
+			Severity:    SeverityError,
+			Check:       checkWindowsShell,
+		},
+		{
+			Name:        "run-script-syntax",
+			Description: "bash and sh run scripts must parse",
+			Severity:    SeverityError,
+			Check:       scripts.check(checkRunScriptSyntax),
+			done:        scripts.reset,
+		},
+		{
+			Name:        "untrusted-expression",
+			Description: "${{ }} in run scripts must not hold untrusted input, which can inject code",
+			Severity:    SeverityError,
+			Check:       checkUntrustedExpression,
+		},
+		{
+			Name:        "missing-pipefail",
+			Description: "pipelines in run scripts should fail when any of their commands does",
+			Severity:    SeverityWarning,
+			Check:       scripts.check(checkMissingPipefail),
+			done:        scripts.reset,
+		},
+		{
+			Name:        "unchecked-cd",
+			Description: "cd in run scripts should stop the script when it fails",
+			Severity:    SeverityWarning,
+			Check:       scripts.check(checkUncheckedCd),
+			done:        scripts.reset,
+		},
+		{
+			Name:        "negated-ignore-pattern",
+			Description: "! patterns belong in branches, tags and paths, not in their ignore lists",
+			Severity:    SeverityWarning,
+			Check:       checkNegatedIgnorePattern,
+		},
+		{
+			Name:        "timeout-minutes",
+			Description: "jobs should set timeout-minutes, the default is 360",
+			Severity:    SeverityWarning,
+			Check:       checkTimeoutMinutes,
+		},
+		{
+			Name:        "env-shadowing",
+			Description: "an env variable overrides the value set at an enclosing level",
+			Severity:    SeverityInfo,
+			Check:       checkEnvShadowing,
+		},
+		{
+			Name:        "undefined-env-reference",
+			Description: "env.<name> references must name a variable of the workflow, job or step env",
+			Severity:    SeverityWarning,
+			Check:       checkUndefinedEnvReference,
+		},
+		{
+			Name:        "concurrency-group-collision",
+			Description: "workflows sharing a concurrency group cancel or queue behind each other",
+			Severity:    SeverityWarning,
+			CheckAll:    checkConcurrencyGroupCollision,
+		},
+	}
+}
+
+func sortedJobIDs(w Workflow) []string {
+	return slices.Sorted(maps.Keys(w.Jobs))
+}
+
+func jobPath(jobID string) string {
+	return fmt.Sprintf("Jobs[%q]", jobID)
+}
+
+func stepPath(jobID string, i int) string {
+	return fmt.Sprintf("Jobs[%q].Steps[%d]", jobID, i)
+}
+
+func checkDuplicateStepID(w Workflow) []Diagnostic {
+	var diags []Diagnostic
+	for _, jobID := range sortedJobIDs(w) {
+		first := make(map[string]int)
+		for i, s := range w.Jobs[jobID].Steps {
+			if s.ID == "" {
+				continue
+			}
+
+			if j, ok := first[s.ID]; ok {
+				diags = append(diags, Diagnostic{
+					Path:    stepPath(jobID, i) + ".ID",
+					Message: fmt.Sprintf("step id %q is already used by Steps[%d]", s.ID, j),
+				})
+				continue
+			}
+
+			first[s.ID] = i
+		}
+	}
+
+	return diags
+}
+
+var (
+	expressionPattern   = regexp.MustCompile(`\$\{\{(.*?)\}\}`)
+	stepsContextPattern = regexp.MustCompile(`(?:^|[^\w.-])steps\.([A-Za-z_][\w-]*)`)
+)
+
+// expressionsIn returns the contents of every ${{ }} in val
+func expressionsIn(val string) []string {
+	var exprs []string
+	for _, m := range expressionPattern.FindAllStringSubmatch(val, -1) {
+		exprs = append(exprs, m[1])
+	}
+
+	return exprs
+}
+
+// stepReferences returns the step IDs referenced through the steps context.
+// If conditions are always expressions, so bare is true for them.
+func stepReferences(val string, bare bool) []string {
+	exprs := []string{val}
+	if !bare {
+		exprs = expressionsIn(val)
+	}
+
+	var ids []string
+	for _, expr := range exprs {
+		for _, m := range stepsContextPattern.FindAllStringSubmatch(expr, -1) {
+			ids = append(ids, m[1])
+		}
+	}
+
+	return ids
+}
+
+// stepStrings returns every string field of a step which may contain expressions, keyed by field path
+func stepStrings(s Step) map[string]string {
+	vals := map[string]string{
+		"Name":             s.Name,
+		"Run":              s.Run,
+		"WorkingDirectory": s.WorkingDirectory,
+	}
+
+	for k, v := range s.Env {
+		vals[fmt.Sprintf("Env[%q]", k)] = v
+	}
+
+	for k, v := range s.With {
+		if str, ok := v.(string); ok {
+			vals[fmt.Sprintf("With[%q]", k)] = str
+		}
+	}
+
+	return vals
+}
+
+func checkStepOutputReference(w Workflow) []Diagnostic {
+	var diags []Diagnostic
+	for _, jobID := range sortedJobIDs(w) {
+		job := w.Jobs[jobID]
+
+		index := make(map[string]int)
+		for i, s := range job.Steps {
+			if _, ok := index[s.ID]; !ok && s.ID != "" {
+				index[s.ID] = i
+			}
+		}
+
+		check := func(path string, at int, refs []string) {
+			for _, id := range refs {
+				j, ok := index[id]
+				switch {
+				case !ok:
+					diags = append(diags, Diagnostic{
+						Path:    path,
+						Message: fmt.Sprintf("steps.%s does not exist in job %q", id, jobID),
+					})
+				case j >= at:
+					diags = append(diags, Diagnostic{
+						Path:    path,
+						Message: fmt.Sprintf("steps.%s refers to Steps[%d], which has not run yet", id, j),
+					})
+				}
+			}
+		}
+
+		for i, s := range job.Steps {
+			check(stepPath(jobID, i)+".If", i, stepReferences(s.If, true))
+
+			fields := stepStrings(s)
+			for _, field := range slices.Sorted(maps.Keys(fields)) {
+				check(stepPath(jobID, i)+"."+field, i, stepReferences(fields[field], false))
+			}
+		}
+
+		// job outputs are evaluated after every step has run
+		for _, k := range slices.Sorted(maps.Keys(job.Outputs)) {
+			check(fmt.Sprintf("%s.Outputs[%q]", jobPath(jobID), k), len(job.Steps), stepReferences(job.Outputs[k], false))
+		}
+	}
+
+	return diags
+}
+
+func checkStepUsesAndRun(w Workflow) []Diagnostic {
+	var diags []Diagnostic
+	for _, jobID := range sortedJobIDs(w) {
+		for i, s := range w.Jobs[jobID].Steps {
+			if s.Uses != "" && s.Run != "" {
+				diags = append(diags, Diagnostic{
+					Path:    stepPath(jobID, i),
+					Message: "step sets both uses and run",
+				})
+			}
+		}
+	}
+
+	return diags
+}
+
+func checkJobUsesAndSteps(w Workflow) []Diagnostic {
+	var diags []Diagnostic
+	for _, jobID := range sortedJobIDs(w) {
+		job := w.Jobs[jobID]
+		if job.Uses != "" && len(job.Steps) > 0 {
+			diags = append(diags, Diagnostic{
+				Path:    jobPath(jobID),
+				Message: "job sets both uses and steps",
+			})
+		}
+	}
+
+	return diags
+}
+
+func checkShellOnUses(w Workflow) []Diagnostic {
+	var diags []Diagnostic
+	for _, jobID := range sortedJobIDs(w) {
+		for i, s := range w.Jobs[jobID].Steps {
+			if s.Uses != "" && s.Shell != "" {
+				diags = append(diags, Diagnostic{
+					Path:    stepPath(jobID, i) + ".Shell",
+					Message: "shell has no effect on a uses step",
+				})
+			}
+		}
+	}
+
+	return diags
+}
+
+func checkTimeoutMinutes(w Workflow) []Diagnostic {
+	var diags []Diagnostic
+	for _, jobID := range sortedJobIDs(w) {
+		job := w.Jobs[jobID]
+		// reusable workflow jobs can't set timeout-minutes, their own jobs do
+		if job.Uses != "" || !job.TimeoutMinutes.IsZero() {
+			continue
+		}
+
+		diags = append(diags, Diagnostic{
+			Path:    jobPath(jobID) + ".TimeoutMinutes",
+			Message: "timeout-minutes is not set, the job may run for up to 6 hours",
+		})
+	}
+
+	return diags
+}
+
+func checkConcurrencyGroupCollision(ws []Workflow) []Diagnostic {
+	type usage struct {
+		workflow string
+		path     string
+		group    string
+	}
+
+	groups := make(map[string][]usage)
+	add := func(w Workflow, c Concurrency, path string) {
+		if c.Group == "" || c.Shared {
+			return
+		}
+
+		key := concurrencyKey(w, c.Group)
+		groups[key] = append(groups[key], usage{w.GetFilename(), path, c.Group})
+	}
+
+	for _, w := range ws {
+		add(w, w.Concurrency, "Concurrency.Group")
+		for _, jobID := range sortedJobIDs(w) {
+			add(w, w.Jobs[jobID].Concurrency, jobPath(jobID)+".Concurrency.Group")
+		}
+	}
+
+	var diags []Diagnostic
+	for _, group := range slices.Sorted(maps.Keys(groups)) {
+		usages := groups[group]
+
+		workflows := make(map[string]bool)
+		for _, u := range usages {
+			workflows[u.workflow] = true
+		}
+
+		if len(workflows) < 2 {
+			continue
+		}
+
+		for _, u := range usages {
+			var others []string
+			for _, o := range usages {
+				if o.workflow != u.workflow {
+					others = append(others, o.workflow)
+				}
+			}
+			slices.Sort(others)
+
+			diags = append(diags, Diagnostic{
+				Workflow: u.workflow,
+				Path:     u.path,
+				Message:  fmt.Sprintf("concurrency group %q is also used by %s", u.group, strings.Join(slices.Compact(others), ", ")),
+			})
+		}
+	}
+
+	return diags
+}
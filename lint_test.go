@@ -0,0 +1,211 @@
+package gocto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cakehappens/gocto/expressions"
+)
+
+type lintFound struct {
+	rule string
+	path string
+}
+
+func TestLint(t *testing.T) {
+	type testCase struct {
+		name     string
+		wf       Workflow
+		expected []lintFound
+	}
+
+	cases := []testCase{
+		{
+			name: "clean",
+			wf: Workflow{
+				Name: "lint",
+				On:   WorkflowOn{Push: &OnPush{}},
+				Jobs: map[string]Job{
+					"build": {
+						RunsOn:         StringOrSlice{"ubuntu-latest"},
+						TimeoutMinutes: IntValue(10),
+						Steps:          []Step{{Uses: "actions/checkout@v4"}, {Run: "make", Shell: ShellBash}},
+					},
+				},
+			},
+		},
+		{
+			name: "duplicate step id",
+			wf: Workflow{
+				Name: "lint",
+				On:   WorkflowOn{Push: &OnPush{}},
+				Jobs: map[string]Job{
+					"build": {
+						RunsOn:         StringOrSlice{"ubuntu-latest"},
+						TimeoutMinutes: IntValue(10),
+						Steps:          []Step{{ID: "version", Run: "make"}, {ID: "version", Uses: "actions/cache@v4"}},
+					},
+				},
+			},
+			expected: []lintFound{{"duplicate-step-id", `Jobs["build"].Steps[1].ID`}},
+		},
+		{
+			name: "step output references",
+			wf: Workflow{
+				Name: "lint",
+				On:   WorkflowOn{Push: &OnPush{}},
+				Jobs: map[string]Job{
+					"build": {
+						RunsOn:         StringOrSlice{"ubuntu-latest"},
+						TimeoutMinutes: IntValue(10),
+						Outputs: map[string]string{
+							"version": expressions.StepOutput("version", "value").String(),
+							"missing": expressions.StepOutput("nope", "value").String(),
+						},
+						Steps: []Step{
+							{ID: "early", Run: "echo ${{ steps.version.outputs.value }}"},
+							{ID: "version", Run: "echo value=1 >> $GITHUB_OUTPUT"},
+						},
+					},
+				},
+			},
+			expected: []lintFound{
+				{"step-output-reference", `Jobs["build"].Steps[0].Run`},
+				{"step-output-reference", `Jobs["build"].Outputs["missing"]`},
+			},
+		},
+		{
+			name: "step uses and run",
+			wf: Workflow{
+				Name: "lint",
+				On:   WorkflowOn{Push: &OnPush{}},
+				Jobs: map[string]Job{
+					"build": {
+						RunsOn:         StringOrSlice{"ubuntu-latest"},
+						TimeoutMinutes: IntValue(10),
+						Steps:          []Step{{Uses: "actions/cache@v4", Run: "make"}},
+					},
+				},
+			},
+			expected: []lintFound{{"step-uses-and-run", `Jobs["build"].Steps[0]`}},
+		},
+		{
+			name: "job uses and steps",
+			wf: Workflow{
+				Name: "lint",
+				On:   WorkflowOn{Push: &OnPush{}},
+				Jobs: map[string]Job{
+					"call": {
+						Uses:  "./.github/workflows/other.yml",
+						Steps: []Step{{Run: "make"}},
+					},
+				},
+			},
+			expected: []lintFound{{"job-uses-and-steps", `Jobs["call"]`}},
+		},
+		{
+			name: "shell on uses",
+			wf: Workflow{
+				Name: "lint",
+				On:   WorkflowOn{Push: &OnPush{}},
+				Jobs: map[string]Job{
+					"build": {
+						RunsOn:         StringOrSlice{"ubuntu-latest"},
+						TimeoutMinutes: IntValue(10),
+						Steps:          []Step{{Uses: "actions/checkout@v4", Shell: ShellBash}},
+					},
+				},
+			},
+			expected: []lintFound{{"shell-on-uses", `Jobs["build"].Steps[0].Shell`}},
+		},
+		{
+			name: "no timeout",
+			wf: Workflow{
+				Name: "lint",
+				On:   WorkflowOn{Push: &OnPush{}},
+				Jobs: map[string]Job{
+					"build": {
+						RunsOn: StringOrSlice{"ubuntu-latest"},
+						Steps:  []Step{{Run: "make"}},
+					},
+				},
+			},
+			expected: []lintFound{{"timeout-minutes", `Jobs["build"].TimeoutMinutes`}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []lintFound
+			for _, d := range Lint(tc.wf) {
+				assert.Equal(t, "lint.yml", d.Workflow)
+				got = append(got, lintFound{d.Rule, d.Path})
+			}
+
+			assert.ElementsMatch(t, tc.expected, got)
+		})
+	}
+}
+
+func TestLinterConfiguration(t *testing.T) {
+	type testCase struct {
+		name      string
+		linter    *Linter
+		workflows []Workflow
+		expected  []lintFound
+		severity  Severity
+	}
+
+	cases := []testCase{
+		{
+			name:   "disabled rules and severity",
+			linter: NewLinter().Disable("shell-on-uses").SetSeverity("timeout-minutes", SeverityError),
+			workflows: []Workflow{{
+				Name: "lint",
+				On:   WorkflowOn{Push: &OnPush{}},
+				Jobs: map[string]Job{
+					"build": {
+						RunsOn: StringOrSlice{"ubuntu-latest"},
+						Steps:  []Step{{Uses: "actions/checkout@v4", Shell: ShellBash}},
+					},
+				},
+			}},
+			expected: []lintFound{{"timeout-minutes", `Jobs["build"].TimeoutMinutes`}},
+			severity: SeverityError,
+		},
+		{
+			name:   "distinct concurrency groups",
+			linter: NewLinter(),
+			workflows: []Workflow{
+				{Name: "lint", On: WorkflowOn{Push: &OnPush{}}, Concurrency: Concurrency{Group: "deploy"}},
+				{Name: "no group", On: WorkflowOn{Push: &OnPush{}}},
+			},
+		},
+		{
+			name:   "concurrency group collision",
+			linter: NewLinter(),
+			workflows: []Workflow{
+				{Name: "lint", On: WorkflowOn{Push: &OnPush{}}, Concurrency: Concurrency{Group: "deploy"}},
+				{Name: "other", On: WorkflowOn{Push: &OnPush{}}, Concurrency: Concurrency{Group: "deploy"}},
+			},
+			expected: []lintFound{
+				{"concurrency-group-collision", "Concurrency.Group"},
+				{"concurrency-group-collision", "Concurrency.Group"},
+			},
+			severity: SeverityWarning,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []lintFound
+			for _, d := range tc.linter.LintAll(tc.workflows...) {
+				assert.Equal(t, tc.severity, d.Severity)
+				got = append(got, lintFound{d.Rule, d.Path})
+			}
+
+			assert.ElementsMatch(t, tc.expected, got)
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package gocto
+
+import (
+	"encoding/json"
+	"maps"
+	"reflect"
+	"slices"
+)
+
+// https://docs.github.com/en/actions/how-tos/write-workflows/choose-what-workflows-do/run-job-variations
+
+// Combinations expands the matrix into the combinations GitHub runs a job for:
+// the cartesian product of Map, minus every combination matching an Exclude entry,
+// with each Include entry either added to every combination whose original values it doesn't overwrite,
+// or appended as a new combination when it can't be added to any.
+// Expressions are not evaluated: a matrix which is an expression, or has KeyExpressions, has no combinations.
+// Numbers are an int when they are integers and a float64 otherwise, like expressions see them.
+func (m *Matrix) Combinations() []map[string]any {
+	if m == nil || m.Expression != "" || len(m.KeyExpressions) > 0 {
+		return nil
+	}
+
+	keys := slices.Sorted(maps.Keys(m.Map))
+
+	var combos []map[string]any
+	if len(keys) > 0 {
+		combos = []map[string]any{{}}
+		for _, k := range keys {
+			var next []map[string]any
+			for _, combo := range combos {
+				for _, v := range m.Map[k] {
+					c := maps.Clone(combo)
+					c[k] = matrixValue(v)
+					next = append(next, c)
+				}
+			}
+			combos = next
+		}
+	}
+
+	combos = slices.DeleteFunc(combos, func(combo map[string]any) bool {
+		for _, exclude := range m.Exclude {
+			if matchesAll(combo, exclude) {
+				return true
+			}
+		}
+		return false
+	})
+
+	original := len(combos)
+	for _, include := range m.Include {
+		added := false
+		for _, combo := range combos[:original] {
+			if !extendsOriginal(combo, include, m.Map) {
+				continue
+			}
+
+			for k, v := range include {
+				combo[k] = matrixValue(v)
+			}
+			added = true
+		}
+
+		if !added {
+			c := make(map[string]any, len(include))
+			for k, v := range include {
+				c[k] = matrixValue(v)
+			}
+			combos = append(combos, c)
+		}
+	}
+
+	return combos
+}
+
+func matchesAll(combo map[string]any, entry map[string]any) bool {
+	for k, v := range entry {
+		if !reflect.DeepEqual(combo[k], matrixValue(v)) {
+			return false
+		}
+	}
+	return true
+}
+
+// extendsOriginal reports whether include can be added to combo without overwriting any of its original matrix values
+func extendsOriginal(combo map[string]any, include map[string]any, original map[string][]any) bool {
+	for k, v := range include {
+		if _, ok := original[k]; ok && !reflect.DeepEqual(combo[k], matrixValue(v)) {
+			return false
+		}
+	}
+	return true
+}
+
+// matrixValue is v like expressions see it, numbers read from a document are an int or a float64
+func matrixValue(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return int(i)
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, e := range v {
+			out[k] = matrixValue(e)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			out[i] = matrixValue(e)
+		}
+		return out
+	}
+
+	return v
+}
@@ -0,0 +1,58 @@
+package gocto
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// JobStages groups the job IDs into stages, every job only needs jobs of earlier stages.
+// Jobs within a stage are sorted, unknown needs and cycles are an error
+func (w Workflow) JobStages() ([][]string, error) {
+	for _, jobID := range sortedJobIDs(w) {
+		for _, need := range w.Jobs[jobID].Needs {
+			if _, ok := w.Jobs[need]; !ok {
+				return nil, fmt.Errorf("%s.Needs: no job %q", jobPath(jobID), need)
+			}
+		}
+	}
+
+	done := make(map[string]bool)
+	var stages [][]string
+	for len(done) < len(w.Jobs) {
+		var stage []string
+		for _, jobID := range sortedJobIDs(w) {
+			if done[jobID] {
+				continue
+			}
+
+			ready := true
+			for _, need := range w.Jobs[jobID].Needs {
+				ready = ready && done[need]
+			}
+
+			if ready {
+				stage = append(stage, jobID)
+			}
+		}
+
+		if len(stage) == 0 {
+			var remaining []string
+			for jobID := range maps.Keys(w.Jobs) {
+				if !done[jobID] {
+					remaining = append(remaining, jobID)
+				}
+			}
+			slices.Sort(remaining)
+			return nil, fmt.Errorf("jobs %s need each other in a cycle", strings.Join(remaining, ", "))
+		}
+
+		for _, jobID := range stage {
+			done[jobID] = true
+		}
+		stages = append(stages, stage)
+	}
+
+	return stages, nil
+}
@@ -0,0 +1,293 @@
+package gocto
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/cakehappens/gocto/internal/util"
+)
+
+const (
+	DefaultLockfileName = "gocto.lock"
+)
+
+// Lockfile records the commit SHA each remote action reference was pinned to,
+// it is meant to be committed next to the generated workflows
+type Lockfile struct {
+	// Actions is keyed by owner/repo@ref, paths within a repository share an entry
+	Actions map[string]string `json:"actions"`
+}
+
+// ReadLockfile reads a lockfile, a missing file is an empty lockfile
+func ReadLockfile(filename string) (*Lockfile, error) {
+	lock := &Lockfile{
+		Actions: make(map[string]string),
+	}
+
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return lock, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, lock); err != nil {
+		return nil, fmt.Errorf("parsing lockfile %s: %w", filename, err)
+	}
+
+	if lock.Actions == nil {
+		lock.Actions = make(map[string]string)
+	}
+
+	return lock, nil
+}
+
+func (l *Lockfile) WriteFile(filename string) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filename, append(data, '\n'), 0o644)
+}
+
+func lockKey(ref ActionRef) string {
+	return ref.Repository() + "@" + ref.Ref
+}
+
+func (l *Lockfile) Lookup(ref ActionRef) (string, bool) {
+	if l == nil {
+		return "", false
+	}
+
+	sha, ok := l.Actions[lockKey(ref)]
+	return sha, ok
+}
+
+func (l *Lockfile) Set(ref ActionRef, sha string) {
+	if l.Actions == nil {
+		l.Actions = make(map[string]string)
+	}
+
+	l.Actions[lockKey(ref)] = sha
+}
+
+// RefFor finds the ref a repository's SHA was resolved from.
+// When several refs point at the same SHA the longest, most specific, one wins, e.g. v4.1.1 over v4
+func (l *Lockfile) RefFor(repository, sha string) (string, bool) {
+	if l == nil {
+		return "", false
+	}
+
+	var found string
+	for _, key := range slices.Sorted(maps.Keys(l.Actions)) {
+		repo, ref, _ := strings.Cut(key, "@")
+		if repo != repository || l.Actions[key] != sha {
+			continue
+		}
+
+		if len(ref) > len(found) {
+			found = ref
+		}
+	}
+
+	return found, found != ""
+}
+
+// Annotate adds the ref each pinned SHA was resolved from as a trailing comment, e.g.
+//
+//	uses: actions/checkout@b4ffde65f46336ab88eb53be808477a3936bae11 # v4.1.1
+func (l *Lockfile) Annotate(doc []byte) ([]byte, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(doc, &node); err != nil {
+		return nil, err
+	}
+
+	util.WalkYAMLMappings(&node, func(key, value *yaml.Node) {
+		if key.Value != "uses" || value.Kind != yaml.ScalarNode {
+			return
+		}
+
+		ref, err := ParseActionRef(value.Value)
+		if err != nil || ref.Kind != ActionRefRemote || !ref.IsPinned() {
+			return
+		}
+
+		if tag, ok := l.RefFor(ref.Repository(), ref.Ref); ok {
+			value.LineComment = tag
+		}
+	})
+
+	return util.EncodeYAML(&node)
+}
+
+// ActionResolver resolves a remote reference's branch or tag to a commit SHA
+type ActionResolver interface {
+	Resolve(ctx context.Context, ref ActionRef) (string, error)
+}
+
+// GitResolver resolves references with git ls-remote
+type GitResolver struct {
+	// BaseURL defaults to https://github.com
+	BaseURL string
+}
+
+func (g GitResolver) Resolve(ctx context.Context, ref ActionRef) (string, error) {
+	baseURL := g.BaseURL
+	if baseURL == "" {
+		baseURL = "https://github.com"
+	}
+
+	url := strings.TrimSuffix(baseURL, "/") + "/" + ref.Repository()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", "ls-remote", url, ref.Ref, ref.Ref+"^{}")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git ls-remote %s: %w: %s", url, err, strings.TrimSpace(stderr.String()))
+	}
+
+	found := make(map[string]string)
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		sha, name, ok := strings.Cut(line, "\t")
+		if ok {
+			found[name] = sha
+		}
+	}
+
+	// peeled annotated tags first, they point at the commit rather than the tag object
+	for _, name := range []string{
+		"refs/tags/" + ref.Ref + "^{}",
+		"refs/tags/" + ref.Ref,
+		"refs/heads/" + ref.Ref,
+	} {
+		if sha, ok := found[name]; ok {
+			return sha, nil
+		}
+	}
+
+	return "", fmt.Errorf("ref %s not found in %s", ref.Ref, url)
+}
+
+// Pinner rewrites remote references to the commit SHAs recorded in a Lockfile
+type Pinner struct {
+	Lockfile *Lockfile
+	// Resolver is used for references missing from the Lockfile, which is updated with the result.
+	// When nil, missing references are an error
+	Resolver ActionResolver
+}
+
+// Pin rewrites every remote Job.Uses and Step.Uses of the workflow to a commit SHA
+func (p Pinner) Pin(ctx context.Context, w *Workflow) error {
+	if p.Lockfile == nil {
+		return errors.New("pinner has no lockfile")
+	}
+
+	return eachUses(w, func(path string, uses *string) error {
+		ref, err := ParseActionRef(*uses)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		if ref.Kind != ActionRefRemote || ref.IsPinned() {
+			return nil
+		}
+
+		sha, ok := p.Lockfile.Lookup(ref)
+		if !ok {
+			if p.Resolver == nil {
+				return fmt.Errorf("%s: %s is not in the lockfile", path, ref)
+			}
+
+			sha, err = p.Resolver.Resolve(ctx, ref)
+			if err != nil {
+				return fmt.Errorf("%s: resolving %s: %w", path, ref, err)
+			}
+
+			if !IsCommitSHA(sha) {
+				return fmt.Errorf("%s: resolving %s: %q is not a commit SHA", path, ref, sha)
+			}
+
+			p.Lockfile.Set(ref, sha)
+		}
+
+		*uses = ref.WithRef(sha).String()
+		return nil
+	})
+}
+
+// Check reports every reference which cannot be pinned from the Lockfile alone,
+// and every SHA reference which the Lockfile does not know about.
+// It never resolves references or modifies the Lockfile.
+func (p Pinner) Check(w Workflow) error {
+	var errs []error
+
+	_ = eachUses(&w, func(path string, uses *string) error {
+		ref, err := ParseActionRef(*uses)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+			return nil
+		}
+
+		switch {
+		case ref.Kind == ActionRefDocker && !ref.IsPinned():
+			errs = append(errs, fmt.Errorf("%s: %s is not pinned to a digest", path, ref))
+		case ref.Kind != ActionRefRemote:
+		case ref.IsPinned():
+			if _, ok := p.Lockfile.RefFor(ref.Repository(), ref.Ref); !ok {
+				errs = append(errs, fmt.Errorf("%s: %s does not match any lockfile entry for %s", path, ref, ref.Repository()))
+			}
+		default:
+			if _, ok := p.Lockfile.Lookup(ref); !ok {
+				errs = append(errs, fmt.Errorf("%s: %s is not pinned in the lockfile", path, ref))
+			}
+		}
+
+		return nil
+	})
+
+	return errors.Join(errs...)
+}
+
+// eachUses calls fn with every non-empty Job.Uses and Step.Uses in a stable order.
+// The Jobs map and steps are copied before fn is called so that maps and slices shared with other workflows,
+// e.g. a copy of *w kept by the caller, are not modified.
+func eachUses(w *Workflow, fn func(path string, uses *string) error) error {
+	w.Jobs = maps.Clone(w.Jobs)
+	for _, jobID := range slices.Sorted(maps.Keys(w.Jobs)) {
+		job := w.Jobs[jobID]
+		job.Steps = slices.Clone(job.Steps)
+
+		if job.Uses != "" {
+			if err := fn(fmt.Sprintf("Jobs[%q].Uses", jobID), &job.Uses); err != nil {
+				return err
+			}
+		}
+
+		for i := range job.Steps {
+			if job.Steps[i].Uses == "" {
+				continue
+			}
+
+			if err := fn(fmt.Sprintf("Jobs[%q].Steps[%d].Uses", jobID, i), &job.Steps[i].Uses); err != nil {
+				return err
+			}
+		}
+
+		w.Jobs[jobID] = job
+	}
+
+	return nil
+}
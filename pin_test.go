@@ -0,0 +1,269 @@
+package gocto
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	checkoutSHA = "11bd71901bbe5b1630ceea73d27597364c9af683"
+	codeqlSHA   = "662472033e021d55d94146f66f6058822b0b39fd"
+)
+
+type fakeResolver map[string]string
+
+func (f fakeResolver) Resolve(_ context.Context, ref ActionRef) (string, error) {
+	sha, ok := f[ref.Repository()+"@"+ref.Ref]
+	if !ok {
+		return "", fmt.Errorf("unknown ref %s", ref)
+	}
+
+	return sha, nil
+}
+
+func TestParseActionRef(t *testing.T) {
+	type testCase struct {
+		uses     string
+		expected ActionRef
+		pinned   bool
+		err      bool
+	}
+
+	cases := []testCase{
+		{
+			uses:     "actions/checkout@v4",
+			expected: ActionRef{Kind: ActionRefRemote, Owner: "actions", Repo: "checkout", Ref: "v4"},
+		},
+		{
+			uses:     "github/codeql-action/init@" + codeqlSHA,
+			expected: ActionRef{Kind: ActionRefRemote, Owner: "github", Repo: "codeql-action", Path: "init", Ref: codeqlSHA},
+			pinned:   true,
+		},
+		{
+			uses:     "octo-org/example-repo/.github/workflows/reusable.yml@main",
+			expected: ActionRef{Kind: ActionRefRemote, Owner: "octo-org", Repo: "example-repo", Path: ".github/workflows/reusable.yml", Ref: "main"},
+		},
+		{
+			uses:     "./.github/actions/hello",
+			expected: ActionRef{Kind: ActionRefLocal, Path: "./.github/actions/hello"},
+			pinned:   true,
+		},
+		{
+			uses:     "docker://alpine:3.8",
+			expected: ActionRef{Kind: ActionRefDocker, Image: "alpine:3.8"},
+		},
+		{
+			uses: "actions/checkout",
+			err:  true,
+		},
+		{
+			uses: "checkout@v4",
+			err:  true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.uses, func(t *testing.T) {
+			ref, err := ParseActionRef(tc.uses)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, ref)
+			assert.Equal(t, tc.pinned, ref.IsPinned())
+			assert.Equal(t, tc.uses, ref.String())
+		})
+	}
+}
+
+func TestPinnerPin(t *testing.T) {
+	type testCase struct {
+		name         string
+		wf           Workflow
+		resolver     fakeResolver
+		expectedUses []string
+		expectedLock map[string]string
+		err          string
+	}
+
+	cases := []testCase{
+		{
+			name: "remote refs",
+			wf: Workflow{
+				Name: "pin",
+				On:   WorkflowOn{Push: &OnPush{}},
+				Jobs: map[string]Job{
+					"build": {
+						RunsOn: StringOrSlice{"ubuntu-latest"},
+						Steps: []Step{
+							{Uses: "actions/checkout@v4.2.2"},
+							{Uses: "./.github/actions/local"},
+							{Uses: "github/codeql-action/init@v3"},
+							{Run: "make"},
+						},
+					},
+				},
+			},
+			resolver: fakeResolver{
+				"actions/checkout@v4.2.2": checkoutSHA,
+				"github/codeql-action@v3": codeqlSHA,
+			},
+			expectedUses: []string{
+				"actions/checkout@" + checkoutSHA,
+				"./.github/actions/local",
+				"github/codeql-action/init@" + codeqlSHA,
+				"",
+			},
+			expectedLock: map[string]string{
+				"actions/checkout@v4.2.2": checkoutSHA,
+				"github/codeql-action@v3": codeqlSHA,
+			},
+		},
+		{
+			name: "unknown ref",
+			wf: Workflow{
+				Name: "pin",
+				On:   WorkflowOn{Push: &OnPush{}},
+				Jobs: map[string]Job{
+					"build": {
+						RunsOn: StringOrSlice{"ubuntu-latest"},
+						Steps:  []Step{{Uses: "actions/checkout@v5"}},
+					},
+				},
+			},
+			resolver: fakeResolver{},
+			err:      `Jobs["build"].Steps[0].Uses: resolving actions/checkout@v5: unknown ref actions/checkout@v5`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			lock := &Lockfile{}
+			original := mustRender(t, tc.wf)
+
+			wf := tc.wf
+			err := Pinner{Lockfile: lock, Resolver: tc.resolver}.Pin(context.Background(), &wf)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, string(original), string(mustRender(t, tc.wf)), "copies of the workflow are not modified")
+			assert.Equal(t, tc.expectedLock, lock.Actions)
+
+			var uses []string
+			for _, s := range wf.Jobs["build"].Steps {
+				uses = append(uses, s.Uses)
+			}
+			assert.Equal(t, tc.expectedUses, uses)
+
+			// the lockfile alone is now enough for both the source and the pinned workflow
+			require.NoError(t, Pinner{Lockfile: lock}.Check(tc.wf))
+			require.NoError(t, Pinner{Lockfile: lock}.Check(wf))
+
+			doc, err := lock.Annotate(mustRender(t, wf))
+			require.NoError(t, err)
+			for ref, sha := range tc.expectedLock {
+				assert.Contains(t, string(doc), "@"+sha+" # "+ref[strings.LastIndex(ref, "@")+1:]+"\n")
+			}
+		})
+	}
+}
+
+func TestPinnerCheck(t *testing.T) {
+	type testCase struct {
+		name string
+		wf   Workflow
+		lock map[string]string
+		err  string
+	}
+
+	cases := []testCase{
+		{
+			name: "tag in the lockfile",
+			wf: Workflow{
+				Name: "check",
+				On:   WorkflowOn{Push: &OnPush{}},
+				Jobs: map[string]Job{
+					"build": {
+						RunsOn: StringOrSlice{"ubuntu-latest"},
+						Steps:  []Step{{Uses: "actions/checkout@v4.2.2"}, {Uses: "./.github/actions/local"}},
+					},
+				},
+			},
+			lock: map[string]string{"actions/checkout@v4.2.2": checkoutSHA},
+		},
+		{
+			name: "digest in the lockfile",
+			wf: Workflow{
+				Name: "check",
+				On:   WorkflowOn{Push: &OnPush{}},
+				Jobs: map[string]Job{
+					"build": {
+						RunsOn: StringOrSlice{"ubuntu-latest"},
+						Steps:  []Step{{Uses: "actions/checkout@" + checkoutSHA}},
+					},
+				},
+			},
+			lock: map[string]string{"actions/checkout@v4.2.2": checkoutSHA},
+		},
+		{
+			name: "tag missing from the lockfile",
+			wf: Workflow{
+				Name: "check",
+				On:   WorkflowOn{Push: &OnPush{}},
+				Jobs: map[string]Job{
+					"build": {
+						RunsOn: StringOrSlice{"ubuntu-latest"},
+						Steps:  []Step{{Uses: "github/codeql-action/init@v3"}},
+					},
+				},
+			},
+			err: `Jobs["build"].Steps[0].Uses`,
+		},
+		{
+			name: "digest of another tag",
+			wf: Workflow{
+				Name: "check",
+				On:   WorkflowOn{Push: &OnPush{}},
+				Jobs: map[string]Job{
+					"build": {
+						RunsOn: StringOrSlice{"ubuntu-latest"},
+						Steps:  []Step{{Uses: "actions/checkout@" + codeqlSHA}},
+					},
+				},
+			},
+			lock: map[string]string{"actions/checkout@v4.2.2": checkoutSHA},
+			err:  `Jobs["build"].Steps[0].Uses: actions/checkout@` + codeqlSHA + ` does not match any lockfile entry for actions/checkout`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Pinner{Lockfile: &Lockfile{Actions: tc.lock}}.Check(tc.wf)
+			if tc.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
+
+func mustRender(t *testing.T, w Workflow) []byte {
+	t.Helper()
+
+	doc, err := w.Render()
+	require.NoError(t, err)
+	return doc
+}
@@ -0,0 +1,374 @@
+// Package plan simulates which jobs and steps of a workflow run for an event, without running anything.
+//
+// Every step which runs is assumed to succeed, and outputs are not known,
+// so conditions on outputs evaluate as if the outputs were empty.
+package plan
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"sort"
+	"strings"
+
+	"github.com/cakehappens/gocto"
+	"github.com/cakehappens/gocto/expressions"
+	"github.com/cakehappens/gocto/internal/util"
+)
+
+// Event is the synthetic event a workflow run is planned for
+type Event struct {
+	// Name is the event, e.g. push, pull_request or workflow_dispatch
+	Name string
+	// Ref is the fully qualified ref the workflow runs on, e.g. refs/heads/main or refs/tags/v1.2.3
+	Ref string
+	// BaseRef and HeadRef are the target and source branches of a pull request
+	BaseRef string
+	HeadRef string
+	// ChangedPaths are the files changed by the push or pull request
+	ChangedPaths []string
+	// Payload is the webhook payload, available as github.event
+	Payload map[string]any
+}
+
+// Plan is the simulated run of a workflow
+type Plan struct {
+	Workflow  string
+	Event     Event
+	Triggered bool
+	// Reason explains why the workflow was not triggered
+	Reason string
+	Stages []Stage
+}
+
+// Stage is a set of jobs which all only need jobs of earlier stages
+type Stage []JobRun
+
+// JobRun is a single job, or a single combination of a matrix job
+type JobRun struct {
+	ID     string
+	Name   string
+	Matrix map[string]any
+	Runs   bool
+	// Reason explains why the job was skipped
+	Reason string
+	// Uses is the reusable workflow the job calls, it has no steps
+	Uses  string
+	Steps []StepRun
+}
+
+type StepRun struct {
+	Index int
+	Name  string
+	Runs  bool
+	// Reason explains why the step was skipped
+	Reason string
+}
+
+// New plans the run of w for e
+func New(w gocto.Workflow, e Event) (*Plan, error) {
+	p := &Plan{
+		Workflow: w.GetFilename(),
+		Event:    e,
+	}
+
+	ok, reason, err := triggered(w, e)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		p.Reason = reason
+		return p, nil
+	}
+	p.Triggered = true
+
+	stages, err := w.JobStages()
+	if err != nil {
+		return nil, err
+	}
+
+	github := e.GitHubContext(w)
+	inputs := e.InputsContext(w)
+
+	results := make(map[string]string)
+	for _, stage := range stages {
+		var planned Stage
+		for _, jobID := range stage {
+			runs, err := planJob(w, jobID, github, inputs, results)
+			if err != nil {
+				return nil, fmt.Errorf("Jobs[%q]: %w", jobID, err)
+			}
+
+			results[jobID] = "skipped"
+			for _, r := range runs {
+				if r.Runs {
+					results[jobID] = "success"
+				}
+			}
+
+			planned = append(planned, runs...)
+		}
+		p.Stages = append(p.Stages, planned)
+	}
+
+	return p, nil
+}
+
+func planJob(w gocto.Workflow, jobID string, github, inputs map[string]any, results map[string]string) ([]JobRun, error) {
+	job := w.Jobs[jobID]
+
+	needs := make(map[string]any)
+	status := expressions.StatusSuccess
+	for _, need := range job.Needs {
+		needs[need] = map[string]any{
+			"result":  results[need],
+			"outputs": map[string]any{},
+		}
+
+		if results[need] != "success" {
+			status = expressions.StatusSkipped
+		}
+	}
+
+	jobEval := &expressions.Evaluator{
+		Contexts: map[string]any{
+			"github": github,
+			"inputs": inputs,
+			"needs":  needs,
+			"vars":   map[string]any{},
+		},
+		Status: status,
+	}
+
+	runs, err := jobEval.EvaluateCondition(job.If)
+	if err != nil {
+		return nil, err
+	}
+
+	combos := job.Strategy.Matrix.Combinations()
+	if len(combos) == 0 {
+		combos = []map[string]any{nil}
+	}
+
+	var planned []JobRun
+	for _, combo := range combos {
+		r := JobRun{
+			ID:     jobID,
+			Name:   job.Name,
+			Matrix: combo,
+			Runs:   runs,
+			Uses:   job.Uses,
+		}
+
+		if !runs {
+			r.Reason = skipReason(job.If, status)
+			planned = append(planned, r)
+			continue
+		}
+
+		contexts := maps.Clone(jobEval.Contexts)
+		contexts["matrix"] = util.OrEmpty(combo)
+		strategyEval := &expressions.Evaluator{Contexts: contexts}
+		maxParallel, _ := job.Strategy.MaxParallel.Resolve(strategyEval)
+		failFast, _ := job.Strategy.FailFast.Resolve(strategyEval, true)
+		contexts["strategy"] = map[string]any{
+			"fail-fast":    failFast,
+			"job-total":    len(combos),
+			"max-parallel": maxParallel,
+		}
+		contexts["runner"] = runnerContext(job.RunsOn)
+		contexts["job"] = map[string]any{"status": "success"}
+		contexts["secrets"] = map[string]any{}
+
+		if r.Name != "" {
+			if name, err := (&expressions.Evaluator{Contexts: contexts}).Interpolate(r.Name); err == nil {
+				r.Name = name
+			}
+		}
+
+		r.Steps, err = planSteps(w, job, contexts)
+		if err != nil {
+			return nil, err
+		}
+
+		planned = append(planned, r)
+	}
+
+	return planned, nil
+}
+
+func planSteps(w gocto.Workflow, job gocto.Job, contexts map[string]any) ([]StepRun, error) {
+	steps := make(map[string]any)
+	contexts["steps"] = steps
+
+	var planned []StepRun
+	for i, s := range job.Steps {
+		env := make(map[string]any)
+		for _, layer := range []map[string]string{w.Env, job.Env, s.Env} {
+			for k, v := range layer {
+				env[k] = v
+			}
+		}
+
+		stepContexts := maps.Clone(contexts)
+		stepContexts["env"] = env
+
+		runs, err := (&expressions.Evaluator{Contexts: stepContexts}).EvaluateCondition(s.If)
+		if err != nil {
+			return nil, fmt.Errorf("Steps[%d].If: %w", i, err)
+		}
+
+		r := StepRun{
+			Index: i,
+			Name:  s.DisplayName(),
+			Runs:  runs,
+		}
+
+		outcome := "success"
+		if !runs {
+			r.Reason = skipReason(s.If, expressions.StatusSuccess)
+			outcome = "skipped"
+		}
+
+		if s.ID != "" {
+			steps[s.ID] = map[string]any{
+				"outcome":    outcome,
+				"conclusion": outcome,
+				"outputs":    map[string]any{},
+			}
+		}
+
+		planned = append(planned, r)
+	}
+
+	return planned, nil
+}
+
+func skipReason(cond string, status expressions.Status) string {
+	if strings.TrimSpace(cond) == "" && status == expressions.StatusSkipped {
+		return "a needed job was skipped"
+	}
+
+	return fmt.Sprintf("if: %s", strings.TrimSpace(cond))
+}
+
+// GitHubContext is the github context of a run of w for the event
+func (e Event) GitHubContext(w gocto.Workflow) map[string]any {
+	refType, refName := "branch", strings.TrimPrefix(e.Ref, "refs/heads/")
+	if strings.HasPrefix(e.Ref, "refs/tags/") {
+		refType, refName = "tag", strings.TrimPrefix(e.Ref, "refs/tags/")
+	}
+
+	repository := ""
+	if repo, ok := e.Payload["repository"].(map[string]any); ok {
+		repository, _ = repo["full_name"].(string)
+	}
+
+	return map[string]any{
+		"event_name": e.Name,
+		"event":      util.OrEmpty(e.Payload),
+		"ref":        e.Ref,
+		"ref_name":   refName,
+		"ref_type":   refType,
+		"base_ref":   strings.TrimPrefix(e.BaseRef, "refs/heads/"),
+		"head_ref":   strings.TrimPrefix(e.HeadRef, "refs/heads/"),
+		"repository": repository,
+		"workflow":   w.Name,
+	}
+}
+
+// InputsContext merges the inputs of the payload over the defaults of the dispatch or call trigger of w
+func (e Event) InputsContext(w gocto.Workflow) map[string]any {
+	inputs := make(map[string]any)
+	if w.On.Dispatch != nil {
+		for k, in := range w.On.Dispatch.Inputs {
+			inputs[k] = in.Default
+		}
+	}
+
+	if w.On.Call != nil {
+		for k, in := range w.On.Call.Inputs {
+			inputs[k] = in.Default
+		}
+	}
+
+	if payloadInputs, ok := e.Payload["inputs"].(map[string]any); ok {
+		maps.Copy(inputs, payloadInputs)
+	}
+
+	return inputs
+}
+
+func runnerContext(runsOn gocto.StringOrSlice) map[string]any {
+	os := "Linux"
+	for _, label := range runsOn {
+		switch {
+		case strings.Contains(label, "windows"):
+			os = "Windows"
+		case strings.Contains(label, "macos"):
+			os = "macOS"
+		}
+	}
+
+	return map[string]any{
+		"os":   os,
+		"arch": "X64",
+	}
+}
+
+// Print writes the plan in stage order
+func (p *Plan) Print(out io.Writer) {
+	if !p.Triggered {
+		fmt.Fprintf(out, "%s: not triggered by %s %s: %s\n", p.Workflow, p.Event.Name, p.Event.Ref, p.Reason)
+		return
+	}
+
+	fmt.Fprintf(out, "%s: triggered by %s %s\n", p.Workflow, p.Event.Name, p.Event.Ref)
+	for i, stage := range p.Stages {
+		fmt.Fprintf(out, "stage %d\n", i+1)
+		for _, j := range stage {
+			label := j.ID
+			if j.Name != "" && j.Name != j.ID {
+				label += fmt.Sprintf(" (%s)", j.Name)
+			}
+			if len(j.Matrix) > 0 {
+				label += " " + formatMatrix(j.Matrix)
+			}
+
+			if !j.Runs {
+				fmt.Fprintf(out, "  skip %s: %s\n", label, j.Reason)
+				continue
+			}
+
+			if j.Uses != "" {
+				fmt.Fprintf(out, "  run  %s: uses %s\n", label, j.Uses)
+				continue
+			}
+
+			fmt.Fprintf(out, "  run  %s\n", label)
+			for _, s := range j.Steps {
+				if s.Runs {
+					fmt.Fprintf(out, "    run  %d. %s\n", s.Index+1, s.Name)
+				} else {
+					fmt.Fprintf(out, "    skip %d. %s: %s\n", s.Index+1, s.Name, s.Reason)
+				}
+			}
+		}
+	}
+}
+
+func formatMatrix(m map[string]any) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%v", k, m[k]))
+	}
+
+	return "[" + strings.Join(parts, ", ") + "]"
+}
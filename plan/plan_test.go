@@ -0,0 +1,144 @@
+package plan
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cakehappens/gocto"
+)
+
+func testWorkflow() gocto.Workflow {
+	return gocto.Workflow{
+		Name: "CI",
+		On: gocto.WorkflowOn{
+			Push: &gocto.OnPush{
+				OnBranches: &gocto.OnBranches{Branches: []string{"main", "release/**"}},
+				OnTags:     &gocto.OnTags{Tags: []string{"v*"}},
+			},
+			PullRequest: &gocto.OnPullRequest{
+				OnPaths: &gocto.OnPaths{Paths: []string{"**.go", "!docs/**"}},
+			},
+		},
+		Jobs: map[string]gocto.Job{
+			"test": {
+				RunsOn: gocto.StringOrSlice{"${{ matrix.os }}"},
+				Strategy: gocto.Strategy{
+					Matrix: &gocto.Matrix{
+						Map: map[string][]any{
+							"os": {"ubuntu-latest", "windows-latest"},
+						},
+					},
+				},
+				Steps: []gocto.Step{
+					{Uses: "actions/checkout@v4"},
+					{Name: "Lint", If: "matrix.os == 'ubuntu-latest'", Run: "make lint"},
+					{Run: "make test"},
+				},
+			},
+			"release": {
+				If:     "startsWith(github.ref, 'refs/tags/v')",
+				Needs:  gocto.StringOrSlice{"test"},
+				RunsOn: gocto.StringOrSlice{"ubuntu-latest"},
+				Steps:  []gocto.Step{{Run: "make release"}},
+			},
+			"notify": {
+				Needs:  gocto.StringOrSlice{"release"},
+				RunsOn: gocto.StringOrSlice{"ubuntu-latest"},
+				Steps:  []gocto.Step{{Run: "make notify"}},
+			},
+		},
+	}
+}
+
+func TestNew(t *testing.T) {
+	type testCase struct {
+		name       string
+		event      Event
+		triggered  bool
+		jobs       map[string][]bool
+		stepsTaken []bool
+	}
+
+	testCases := []testCase{
+		{
+			name:      "push to main",
+			event:     Event{Name: "push", Ref: "refs/heads/main"},
+			triggered: true,
+			jobs: map[string][]bool{
+				"test":    {true, true},
+				"release": {false},
+				"notify":  {false},
+			},
+		},
+		{
+			name:      "push of a tag",
+			event:     Event{Name: "push", Ref: "refs/tags/v1.0.0"},
+			triggered: true,
+			jobs: map[string][]bool{
+				"test":    {true, true},
+				"release": {true},
+				"notify":  {true},
+			},
+		},
+		{
+			name:  "push to a feature branch",
+			event: Event{Name: "push", Ref: "refs/heads/feature"},
+		},
+		{
+			name:  "pull request only changing docs",
+			event: Event{Name: "pull_request", Ref: "refs/pull/1/merge", BaseRef: "main", ChangedPaths: []string{"docs/main.go"}},
+		},
+		{
+			name:      "pull request changing go files",
+			event:     Event{Name: "pull_request", Ref: "refs/pull/1/merge", BaseRef: "main", ChangedPaths: []string{"docs/README.md", "plan/plan.go"}},
+			triggered: true,
+			jobs: map[string][]bool{
+				"test":    {true, true},
+				"release": {false},
+				"notify":  {false},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := New(testWorkflow(), tc.event)
+			require.NoError(t, err)
+			require.Equal(t, tc.triggered, p.Triggered, p.Reason)
+
+			jobs := make(map[string][]bool)
+			for _, stage := range p.Stages {
+				for _, j := range stage {
+					jobs[j.ID] = append(jobs[j.ID], j.Runs)
+				}
+			}
+
+			if tc.jobs == nil {
+				assert.Empty(t, jobs)
+				return
+			}
+			assert.Equal(t, tc.jobs, jobs)
+		})
+	}
+}
+
+func TestNewSteps(t *testing.T) {
+	p, err := New(testWorkflow(), Event{Name: "push", Ref: "refs/heads/main"})
+	require.NoError(t, err)
+	require.Len(t, p.Stages, 3)
+	require.Len(t, p.Stages[0], 2)
+
+	ubuntu, windows := p.Stages[0][0], p.Stages[0][1]
+	assert.Equal(t, map[string]any{"os": "ubuntu-latest"}, ubuntu.Matrix)
+	assert.True(t, ubuntu.Steps[1].Runs)
+	assert.False(t, windows.Steps[1].Runs)
+	assert.Equal(t, "if: matrix.os == 'ubuntu-latest'", windows.Steps[1].Reason)
+
+	var out bytes.Buffer
+	p.Print(&out)
+	assert.Contains(t, out.String(), "  run  test [os=windows-latest]\n    run  1. actions/checkout@v4\n    skip 2. Lint")
+	assert.Contains(t, out.String(), "  skip notify: a needed job was skipped")
+}
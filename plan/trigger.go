@@ -0,0 +1,75 @@
+package plan
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/cakehappens/gocto"
+)
+
+// triggered decides whether the event triggers the workflow, reason explains a no
+func triggered(w gocto.Workflow, e Event) (ok bool, reason string, err error) {
+	switch e.Name {
+	case "push":
+		if w.On.Push == nil {
+			return false, "no push trigger", nil
+		}
+		if !strings.HasPrefix(e.Ref, "refs/heads/") && !strings.HasPrefix(e.Ref, "refs/tags/") {
+			return false, "", fmt.Errorf("push ref %q is neither refs/heads/ nor refs/tags/", e.Ref)
+		}
+		if !w.On.Push.Matches(e.Ref, e.ChangedPaths) {
+			return false, fmt.Sprintf("%s and the changed paths don't pass the push filters", e.Ref), nil
+		}
+		return true, "", nil
+	case "pull_request", "pull_request_target":
+		on := w.On.PullRequest
+		if e.Name == "pull_request_target" {
+			on = w.On.PullRequestTarget
+		}
+		if on == nil {
+			return false, "no " + e.Name + " trigger", nil
+		}
+		if !on.OnBranches.MatchesBranch(e.BaseRef) {
+			return false, fmt.Sprintf("base branch %q doesn't pass the branch filters", e.BaseRef), nil
+		}
+		if !on.OnPaths.MatchesPaths(e.ChangedPaths) {
+			return false, "the changed paths don't pass the path filters", nil
+		}
+		return true, "", nil
+	case "workflow_dispatch":
+		return w.On.Dispatch != nil, "no workflow_dispatch trigger", nil
+	case "workflow_call":
+		return w.On.Call != nil, "no workflow_call trigger", nil
+	case "schedule":
+		return w.On.Schedule != nil, "no schedule trigger", nil
+	case "workflow_run":
+		if w.On.Run == nil {
+			return false, "no workflow_run trigger", nil
+		}
+		return matchWorkflowRun(w.On.Run, e)
+	}
+
+	return false, fmt.Sprintf("%s events are not supported by the planner", e.Name), nil
+}
+
+func matchWorkflowRun(on *gocto.OnWorkflowRun, e Event) (bool, string, error) {
+	run, _ := e.Payload["workflow_run"].(map[string]any)
+	name, _ := run["name"].(string)
+	if name == "" {
+		if wf, ok := e.Payload["workflow"].(map[string]any); ok {
+			name, _ = wf["name"].(string)
+		}
+	}
+	branch, _ := run["head_branch"].(string)
+
+	if action, _ := e.Payload["action"].(string); len(on.Types) > 0 && !slices.Contains(on.Types, action) {
+		return false, fmt.Sprintf("activity type %q is not one of %s", action, strings.Join(on.Types, ", ")), nil
+	}
+
+	if !on.Matches(name, branch) {
+		return false, fmt.Sprintf("a run of %q on %q doesn't pass the workflow_run filters", name, branch), nil
+	}
+
+	return true, "", nil
+}
@@ -0,0 +1,413 @@
+package gocto
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// GeneratedHeader is prepended to every file a Project renders,
+// it is how files which are no longer part of the project are recognised
+const GeneratedHeader = "# Code generated by gocto; DO NOT EDIT.\n"
+
+// Project is every workflow and composite action of a repository, rendered and checked as one unit
+type Project struct {
+	Workflows []Workflow
+	Actions   []*Action
+	Defaults  ProjectDefaults
+	// Pinner, when set, pins the action references of every workflow as it is rendered
+	Pinner *Pinner
+	// Environments, when set, is checked against the environment of every job
+	Environments *EnvironmentsManifest
+}
+
+// ProjectDefaults are applied to every workflow, or every job, which doesn't set its own
+type ProjectDefaults struct {
+	Permissions Permissions
+	Defaults    Defaults
+	// Env is merged into every workflow's Env, the workflow wins on conflicts
+	Env map[string]string
+	// RunsOn and TimeoutMinutes apply to jobs which run steps, not to reusable workflow jobs
+	RunsOn         StringOrSlice
+	TimeoutMinutes int
+}
+
+func NewProject() *Project {
+	return &Project{}
+}
+
+func (p *Project) AddWorkflow(w ...Workflow) *Project {
+	p.Workflows = append(p.Workflows, w...)
+	return p
+}
+
+func (p *Project) AddAction(a ...*Action) *Project {
+	p.Actions = append(p.Actions, a...)
+	return p
+}
+
+// File is a rendered file, Path is slash separated and relative to the repository root
+type File struct {
+	Path    string
+	Content []byte
+}
+
+// ResolvedWorkflows returns the workflows with the project defaults applied.
+// Filenames must be unique, every reference from one workflow to another
+// workflow or action of the project must resolve, and jobs must deploy to
+// environments of the manifest from the refs they accept
+func (p *Project) ResolvedWorkflows() ([]Workflow, error) {
+	workflows := make([]Workflow, 0, len(p.Workflows))
+	for _, w := range p.Workflows {
+		workflows = append(workflows, p.applyDefaults(w))
+	}
+
+	var errs []error
+	errs = append(errs, checkUniqueFiles(workflows, p.Actions)...)
+	errs = append(errs, checkReferences(workflows, p.Actions)...)
+	errs = append(errs, p.Environments.check(workflows)...)
+
+	return workflows, errors.Join(errs...)
+}
+
+func (p *Project) applyDefaults(w Workflow) Workflow {
+	d := p.Defaults
+	if w.Permissions == (Permissions{}) {
+		w.Permissions = d.Permissions
+	}
+
+	if w.Defaults == (Defaults{}) {
+		w.Defaults = d.Defaults
+	}
+
+	if len(d.Env) > 0 {
+		env := maps.Clone(d.Env)
+		maps.Copy(env, w.Env)
+		w.Env = env
+	}
+
+	if len(d.RunsOn) > 0 || d.TimeoutMinutes != 0 {
+		jobs := make(map[string]Job, len(w.Jobs))
+		for id, job := range w.Jobs {
+			if job.Uses == "" {
+				if len(job.RunsOn) == 0 {
+					job.RunsOn = d.RunsOn
+				}
+
+				if job.TimeoutMinutes.IsZero() {
+					job.TimeoutMinutes = IntValue(d.TimeoutMinutes)
+				}
+			}
+
+			jobs[id] = job
+		}
+		w.Jobs = jobs
+	}
+
+	return w
+}
+
+func checkUniqueFiles(workflows []Workflow, actions []*Action) []error {
+	var errs []error
+
+	workflowNames := make(map[string]string)
+	for _, w := range workflows {
+		filename := w.GetFilename()
+		if other, ok := workflowNames[filename]; ok {
+			errs = append(errs, fmt.Errorf("workflows %q and %q both render to %s", other, w.Name, filename))
+			continue
+		}
+		workflowNames[filename] = w.Name
+	}
+
+	actionNames := make(map[string]string)
+	for _, a := range actions {
+		dirname := a.GetDirname()
+		if other, ok := actionNames[dirname]; ok {
+			errs = append(errs, fmt.Errorf("actions %q and %q both render to %s", other, a.Name, a.GetRelativePathAndFilename()))
+			continue
+		}
+		actionNames[dirname] = a.Name
+	}
+
+	return errs
+}
+
+// checkReferences resolves workflow_run workflow names, local reusable workflows and local actions.
+// Local references outside of the directories the project renders to are not checked.
+func checkReferences(workflows []Workflow, actions []*Action) []error {
+	byName := make(map[string]bool)
+	byPath := make(map[string]Workflow)
+	for _, w := range workflows {
+		byName[w.Name] = true
+		byPath[w.GetRelativePathAndFilename()] = w
+	}
+
+	actionPaths := make(map[string]bool)
+	for _, a := range actions {
+		actionPaths[path.Clean(a.GetRelativePath())] = true
+	}
+
+	workflowsDir := path.Clean(DefaultPathToWorkflows)
+	actionsDir := path.Clean(DefaultPathToActions)
+
+	var errs []error
+	for _, w := range workflows {
+		filename := w.GetFilename()
+
+		if w.On.Run != nil {
+			for i, name := range w.On.Run.Workflows {
+				if !byName[name] {
+					errs = append(errs, fmt.Errorf("%s: On.Run.Workflows[%d]: no workflow named %q", filename, i, name))
+				}
+			}
+		}
+
+		for _, jobID := range sortedJobIDs(w) {
+			job := w.Jobs[jobID]
+
+			if ref, err := job.ActionRef(); err == nil && ref.Kind == ActionRefLocal && path.Dir(path.Clean(ref.Path)) == workflowsDir {
+				target, ok := byPath[path.Clean(ref.Path)]
+				if !ok {
+					errs = append(errs, fmt.Errorf("%s: %s.Uses: no workflow renders to %s", filename, jobPath(jobID), ref.Path))
+				} else {
+					errs = append(errs, checkCall(filename, jobID, job, target)...)
+				}
+			}
+
+			for i, s := range job.Steps {
+				ref, err := s.ActionRef()
+				if err != nil || ref.Kind != ActionRefLocal || path.Dir(path.Clean(ref.Path)) != actionsDir {
+					continue
+				}
+
+				if !actionPaths[path.Clean(ref.Path)] {
+					errs = append(errs, fmt.Errorf("%s: %s.Uses: no action renders to %s", filename, stepPath(jobID, i), ref.Path))
+				}
+			}
+		}
+	}
+
+	return errs
+}
+
+// checkCall checks a job calling a reusable workflow against the workflow_call trigger of the target
+func checkCall(filename, jobID string, job Job, target Workflow) []error {
+	if target.On.Call == nil {
+		return []error{fmt.Errorf("%s: %s.Uses: %s has no workflow_call trigger", filename, jobPath(jobID), target.GetFilename())}
+	}
+
+	var errs []error
+	for _, k := range slices.Sorted(maps.Keys(job.With)) {
+		if _, ok := target.On.Call.Inputs[k]; !ok {
+			errs = append(errs, fmt.Errorf("%s: %s.With[%q]: %s has no input %q", filename, jobPath(jobID), k, target.GetFilename(), k))
+		}
+	}
+
+	for _, k := range slices.Sorted(maps.Keys(target.On.Call.Inputs)) {
+		input := target.On.Call.Inputs[k]
+		if _, ok := job.With[k]; !ok && input.Required != nil && *input.Required && input.Default == "" {
+			errs = append(errs, fmt.Errorf("%s: %s.With: %s requires input %q", filename, jobPath(jobID), target.GetFilename(), k))
+		}
+	}
+
+	return errs
+}
+
+// Render validates and renders every workflow and action of the project
+func (p *Project) Render(ctx context.Context) ([]File, error) {
+	workflows, err := p.ResolvedWorkflows()
+	if err != nil {
+		return nil, err
+	}
+
+	var files []File
+	var errs []error
+	for _, w := range workflows {
+		doc, err := p.renderWorkflow(ctx, w)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", w.GetFilename(), err))
+			continue
+		}
+
+		files = append(files, File{
+			Path:    w.GetRelativePathAndFilename(),
+			Content: append([]byte(GeneratedHeader), doc...),
+		})
+	}
+
+	for _, a := range p.Actions {
+		if err := a.Validate(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		doc, err := a.Render()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", a.GetRelativePathAndFilename(), err))
+			continue
+		}
+
+		files = append(files, File{
+			Path:    path.Clean(a.GetRelativePathAndFilename()),
+			Content: append([]byte(GeneratedHeader), doc...),
+		})
+	}
+
+	return files, errors.Join(errs...)
+}
+
+func (p *Project) renderWorkflow(ctx context.Context, w Workflow) ([]byte, error) {
+	if err := w.Validate(); err != nil {
+		return nil, err
+	}
+
+	if p.Pinner != nil {
+		// pinning rewrites jobs, which must not leak into the project's workflow
+		w.Jobs = maps.Clone(w.Jobs)
+		if err := p.Pinner.Pin(ctx, &w); err != nil {
+			return nil, err
+		}
+	}
+
+	doc, err := w.Render()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.Pinner != nil {
+		return p.Pinner.Lockfile.Annotate(doc)
+	}
+
+	return doc, nil
+}
+
+type FileState string
+
+const (
+	FileStateMissing   FileState = "missing"
+	FileStateOutOfDate FileState = "out of date"
+	// FileStateStale is a generated file which is no longer part of the project
+	FileStateStale FileState = "stale"
+)
+
+// FileStatus is a file which differs between the project and the repository
+type FileStatus struct {
+	File
+	State FileState
+	// Existing is the content of the file in the repository, nil when missing
+	Existing []byte
+}
+
+// Check compares the rendered project with the files under root, only files which differ are returned
+func (p *Project) Check(ctx context.Context, root string) ([]FileStatus, error) {
+	files, err := p.Render(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []FileStatus
+	rendered := make(map[string]bool)
+	for _, f := range files {
+		rendered[f.Path] = true
+
+		existing, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(f.Path)))
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			statuses = append(statuses, FileStatus{File: f, State: FileStateMissing})
+		case err != nil:
+			return nil, err
+		case !bytes.Equal(existing, f.Content):
+			statuses = append(statuses, FileStatus{File: f, State: FileStateOutOfDate, Existing: existing})
+		}
+	}
+
+	generated, err := generatedFiles(root)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range generated {
+		if !rendered[f.Path] {
+			statuses = append(statuses, FileStatus{File: File{Path: f.Path}, State: FileStateStale, Existing: f.Content})
+		}
+	}
+
+	return statuses, nil
+}
+
+// WriteFiles brings the files under root up to date with the project, removing stale generated files.
+// The statuses of the files which were changed are returned
+func (p *Project) WriteFiles(ctx context.Context, root string) ([]FileStatus, error) {
+	statuses, err := p.Check(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range statuses {
+		filename := filepath.Join(root, filepath.FromSlash(s.Path))
+		if s.State == FileStateStale {
+			if err := os.Remove(filename); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+			return nil, err
+		}
+
+		if err := os.WriteFile(filename, s.Content, 0o644); err != nil {
+			return nil, err
+		}
+	}
+
+	return statuses, nil
+}
+
+// generatedFiles finds the workflows and actions under root which start with GeneratedHeader
+func generatedFiles(root string) ([]File, error) {
+	var patterns []string
+	for _, ext := range []string{"*.yml", "*.yaml"} {
+		patterns = append(patterns,
+			path.Join(DefaultPathToWorkflows, ext),
+			path.Join(DefaultPathToActions, "*", "action"+strings.TrimPrefix(ext, "*")),
+		)
+	}
+
+	var files []File
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(filepath.Join(root, filepath.FromSlash(pattern)))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, match := range matches {
+			content, err := os.ReadFile(match)
+			if err != nil {
+				return nil, err
+			}
+
+			if !bytes.HasPrefix(content, []byte(GeneratedHeader)) {
+				continue
+			}
+
+			rel, err := filepath.Rel(root, match)
+			if err != nil {
+				return nil, err
+			}
+
+			files = append(files, File{Path: filepath.ToSlash(rel), Content: content})
+		}
+	}
+
+	return files, nil
+}
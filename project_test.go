@@ -0,0 +1,145 @@
+package gocto
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testProject() *Project {
+	return &Project{
+		Defaults: ProjectDefaults{
+			Env:            map[string]string{"CI": "true", "GOFLAGS": "-mod=mod"},
+			RunsOn:         StringOrSlice{"ubuntu-latest"},
+			TimeoutMinutes: 15,
+		},
+		Workflows: []Workflow{
+			{
+				Name: "Build",
+				On: WorkflowOn{
+					Call: &OnCall{
+						Inputs: map[string]CallInput{
+							"target": {Type: CallInputTypeString, Required: Ptr(true)},
+						},
+					},
+				},
+				Env: map[string]string{"GOFLAGS": "-mod=vendor"},
+				Jobs: map[string]Job{
+					"build": {
+						Steps: []Step{
+							{Uses: "./.github/actions/setup"},
+							{Run: "make ${{ inputs.target }}"},
+						},
+					},
+				},
+			},
+			{
+				Name: "CI",
+				On: WorkflowOn{
+					Push: &OnPush{},
+				},
+				Jobs: map[string]Job{
+					"build": {
+						Uses: "./.github/workflows/build.yml",
+						With: map[string]string{"target": "all"},
+					},
+				},
+			},
+			{
+				Name: "Report",
+				On: WorkflowOn{
+					Run: &OnWorkflowRun{
+						Workflows: []string{"CI"},
+						Types:     []string{"completed"},
+					},
+				},
+				Jobs: map[string]Job{
+					"report": {
+						TimeoutMinutes: IntValue(5),
+						Steps:          []Step{{Run: "echo done"}},
+					},
+				},
+			},
+		},
+		Actions: []*Action{
+			{
+				Name:        "setup",
+				Description: "sets up the toolchain",
+				Runs:        CompositeRuns(Step{Run: "make setup", Shell: ShellBash}),
+			},
+		},
+	}
+}
+
+func TestProjectResolvedWorkflows(t *testing.T) {
+	workflows, err := testProject().ResolvedWorkflows()
+	require.NoError(t, err)
+
+	build := workflows[0]
+	assert.Equal(t, map[string]string{"CI": "true", "GOFLAGS": "-mod=vendor"}, build.Env)
+	assert.Equal(t, StringOrSlice{"ubuntu-latest"}, build.Jobs["build"].RunsOn)
+	assert.Equal(t, IntValue(15), build.Jobs["build"].TimeoutMinutes)
+
+	// reusable workflow jobs can't set runs-on or timeout-minutes
+	ci := workflows[1]
+	assert.Empty(t, ci.Jobs["build"].RunsOn)
+	assert.Zero(t, ci.Jobs["build"].TimeoutMinutes)
+
+	assert.Equal(t, IntValue(5), workflows[2].Jobs["report"].TimeoutMinutes)
+}
+
+func TestProjectReferences(t *testing.T) {
+	p := testProject()
+	p.Workflows[1].Jobs["build"] = Job{
+		Uses: "./.github/workflows/build.yml",
+		With: map[string]string{"nope": "all"},
+	}
+	p.Workflows[2].On.Run.Workflows = []string{"Deploy"}
+	p.Workflows = append(p.Workflows, Workflow{Name: "ci", On: WorkflowOn{Push: &OnPush{}}})
+	p.Actions = nil
+
+	_, err := p.ResolvedWorkflows()
+	require.Error(t, err)
+	assert.ErrorContains(t, err, `workflows "CI" and "ci" both render to ci.yml`)
+	assert.ErrorContains(t, err, `build.yml: Jobs["build"].Steps[0].Uses: no action renders to ./.github/actions/setup`)
+	assert.ErrorContains(t, err, `ci.yml: Jobs["build"].With["nope"]: build.yml has no input "nope"`)
+	assert.ErrorContains(t, err, `ci.yml: Jobs["build"].With: build.yml requires input "target"`)
+	assert.ErrorContains(t, err, `report.yml: On.Run.Workflows[0]: no workflow named "Deploy"`)
+}
+
+func TestProjectWriteFiles(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	p := testProject()
+
+	statuses, err := p.Check(ctx, root)
+	require.NoError(t, err)
+	assert.Len(t, statuses, 4)
+	for _, s := range statuses {
+		assert.Equal(t, FileStateMissing, s.State, s.Path)
+	}
+
+	_, err = p.WriteFiles(ctx, root)
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(root, ".github", "actions", "setup", "action.yml"))
+
+	statuses, err = p.Check(ctx, root)
+	require.NoError(t, err)
+	assert.Empty(t, statuses)
+
+	handWritten := filepath.Join(root, ".github", "workflows", "hand-written.yml")
+	require.NoError(t, os.WriteFile(handWritten, []byte("name: hand written\n"), 0o644))
+
+	p.Workflows = p.Workflows[:2]
+	statuses, err = p.WriteFiles(ctx, root)
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+	assert.Equal(t, FileStateStale, statuses[0].State)
+	assert.Equal(t, ".github/workflows/report.yml", statuses[0].Path)
+	assert.NoFileExists(t, filepath.Join(root, ".github", "workflows", "report.yml"))
+	assert.FileExists(t, handWritten)
+}
@@ -0,0 +1,52 @@
+package gocto
+
+import (
+	"sync"
+)
+
+var registry struct {
+	mu      sync.Mutex
+	project Project
+}
+
+// Register adds a workflow to the DefaultProject, which the gocto command manages.
+// It is meant to be called from an init function of the package passed to gocto -pkg
+func Register(w Workflow) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	registry.project.AddWorkflow(w)
+}
+
+// RegisterAction adds a composite action to the DefaultProject
+func RegisterAction(a *Action) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	registry.project.AddAction(a)
+}
+
+// SetDefaults sets the ProjectDefaults of the DefaultProject
+func SetDefaults(d ProjectDefaults) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	registry.project.Defaults = d
+}
+
+// SetEnvironments sets the EnvironmentsManifest of the DefaultProject
+func SetEnvironments(m *EnvironmentsManifest) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	registry.project.Environments = m
+}
+
+// DefaultProject returns everything registered so far
+func DefaultProject() *Project {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	p := registry.project
+	return &p
+}
@@ -0,0 +1,106 @@
+package gocto
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+
+	"github.com/cakehappens/gocto/internal/diff"
+	"github.com/cakehappens/gocto/internal/util"
+)
+
+// RoundTrip checks a workflow document survives gocto: every key of the document must be rendered again,
+// and parsing the rendered document must give the same workflow, which renders the same document.
+// Shorthands may be rendered differently, e.g. on: push as on: {push: {}}.
+func RoundTrip(doc []byte) error {
+	w, err := ParseWorkflow(doc)
+	if err != nil {
+		return fmt.Errorf("parsing: %w", err)
+	}
+
+	rendered, err := w.Render()
+	if err != nil {
+		return fmt.Errorf("rendering: %w", err)
+	}
+
+	again, err := ParseWorkflow(rendered)
+	if err != nil {
+		return fmt.Errorf("parsing the rendered document: %w", err)
+	}
+
+	renderedAgain, err := again.Render()
+	if err != nil {
+		return fmt.Errorf("rendering again: %w", err)
+	}
+
+	if d := diff.Unified("rendered", "rendered again", rendered, renderedAgain); d != "" {
+		return fmt.Errorf("rendering the parsed rendered document gives a different document:\n%s", d)
+	}
+
+	if !reflect.DeepEqual(w, again) {
+		return errors.New("parsing the rendered document gives a different workflow")
+	}
+
+	var before, after any
+	docJSON, err := util.YAMLToJSON(doc)
+	if err != nil {
+		return err
+	}
+	renderedJSON, err := util.YAMLToJSON(rendered)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(docJSON, &before); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(renderedJSON, &after); err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, path := range lostKeys(before, after, "") {
+		errs = append(errs, fmt.Errorf("%s: not rendered", path))
+	}
+
+	return errors.Join(errs...)
+}
+
+// lostKeys returns the paths of the keys of before which after lacks. Keys with a null value can be left out,
+// but not empty ones, permissions: {} revokes every permission. A scalar in before may be a shorthand for a mapping in after.
+func lostKeys(before, after any, path string) []string {
+	var lost []string
+	switch b := before.(type) {
+	case map[string]any:
+		a, ok := after.(map[string]any)
+		if !ok {
+			return nil
+		}
+
+		for _, k := range slices.Sorted(maps.Keys(b)) {
+			keyPath := path + "." + k
+			if path == "" {
+				keyPath = k
+			}
+
+			if v, ok := a[k]; ok {
+				lost = append(lost, lostKeys(b[k], v, keyPath)...)
+			} else if b[k] != nil {
+				lost = append(lost, keyPath)
+			}
+		}
+	case []any:
+		a, ok := after.([]any)
+		if !ok {
+			return nil
+		}
+
+		for i := range min(len(a), len(b)) {
+			lost = append(lost, lostKeys(b[i], a[i], fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	}
+
+	return lost
+}
@@ -0,0 +1,174 @@
+package gocto
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cakehappens/gocto/expressions"
+)
+
+func TestRoundTripConformance(t *testing.T) {
+	docs, err := filepath.Glob("testdata/conformance/*.yml")
+	require.NoError(t, err)
+	require.NotEmpty(t, docs)
+
+	for _, doc := range docs {
+		t.Run(filepath.Base(doc), func(t *testing.T) {
+			data, err := os.ReadFile(doc)
+			require.NoError(t, err)
+			assert.NoError(t, RoundTrip(data))
+
+			w, err := ParseWorkflowStrict(data)
+			require.NoError(t, err)
+			assert.NoError(t, w.Validate())
+		})
+	}
+}
+
+func TestRoundTripLostKeys(t *testing.T) {
+	doc := `name: Lost
+on: push
+permissions:
+  contents: read
+  artifact-metadata: write
+jobs: {}
+`
+	assert.EqualError(t, RoundTrip([]byte(doc)), "permissions.artifact-metadata: not rendered")
+
+	doc = `name: Lost
+on: push
+env: {}
+jobs: {}
+`
+	// empty mappings which are not rendered again are reported too, GitHub tells permissions: {} from no permissions
+	assert.Error(t, RoundTrip([]byte(doc)))
+}
+
+func TestRenderShorthands(t *testing.T) {
+	w := Workflow{
+		Name: "Shorthands",
+		On: WorkflowOn{
+			Push:     &OnPush{},
+			Schedule: &OnSchedule{},
+			Dispatch: &OnDispatch{Inputs: map[string]OnDispatchInput{
+				"count":   {Type: OnDispatchInputTypeNumber, Default: "2"},
+				"version": {Type: OnDispatchInputTypeString, Default: "1.10"},
+			}},
+		},
+		Permissions: Permissions{All: AccessLevelWrite},
+		Jobs: map[string]Job{
+			"build": {
+				Container: Container{Image: "node:18"},
+				With:      map[string]string{"dry-run": "true", "ratio": "0.5", "go": "1.10"},
+			},
+		},
+	}
+
+	doc, err := w.Render()
+	require.NoError(t, err)
+	assert.NotContains(t, string(doc), "schedule")
+	assert.Contains(t, string(doc), "permissions: write-all\n")
+
+	w.Permissions = Permissions{All: AccessLevelNone}
+	doc, err = w.Render()
+	require.NoError(t, err)
+	assert.Contains(t, string(doc), "permissions: {}\n")
+	assert.Contains(t, string(doc), "container: node:18\n")
+	assert.Contains(t, string(doc), "dry-run: true\n")
+	assert.Contains(t, string(doc), "ratio: 0.5\n")
+	assert.Contains(t, string(doc), "go: \"1.10\"\n")
+	assert.Contains(t, string(doc), "default: 2\n")
+	assert.Contains(t, string(doc), "default: \"1.10\"\n")
+}
+
+func TestUnmarshalUnions(t *testing.T) {
+	type testCase struct {
+		name     string
+		doc      string
+		expected Job
+	}
+
+	testCases := []testCase{
+		{
+			name:     "runs-on string",
+			doc:      `{"runs-on":"ubuntu-latest"}`,
+			expected: Job{RunsOn: StringOrSlice{"ubuntu-latest"}},
+		},
+		{
+			name:     "runs-on list",
+			doc:      `{"runs-on":["self-hosted","linux"]}`,
+			expected: Job{RunsOn: StringOrSlice{"self-hosted", "linux"}},
+		},
+		{
+			name:     "secrets inherit",
+			doc:      `{"secrets":"inherit"}`,
+			expected: Job{Secrets: &Secrets{Inherit: true}},
+		},
+		{
+			name:     "secrets map",
+			doc:      `{"secrets":{"token":"${{ secrets.TOKEN }}"}}`,
+			expected: Job{Secrets: &Secrets{Map: map[string]string{"token": "${{ secrets.TOKEN }}"}}},
+		},
+		{
+			name: "matrix int",
+			doc:  `{"strategy":{"matrix":{"shard":[2]}}}`,
+			expected: Job{Strategy: Strategy{Matrix: &Matrix{
+				Map: map[string][]any{"shard": {json.Number("2")}},
+			}}},
+		},
+		{
+			name: "matrix include bool",
+			doc:  `{"strategy":{"matrix":{"go":["1.25"],"include":[{"go":"1.26","experimental":true}]}}}`,
+			expected: Job{Strategy: Strategy{Matrix: &Matrix{
+				Map:     map[string][]any{"go": {"1.25"}},
+				Include: []map[string]any{{"go": "1.26", "experimental": true}},
+			}}},
+		},
+		{
+			name: "matrix objects and key expressions",
+			doc:  `{"strategy":{"matrix":{"platform":[{"os":"linux","arch":"amd64"}],"go":"${{ fromJSON(needs.setup.outputs.go) }}"}}}`,
+			expected: Job{Strategy: Strategy{Matrix: &Matrix{
+				Map:            map[string][]any{"platform": {map[string]any{"os": "linux", "arch": "amd64"}}},
+				KeyExpressions: map[string]expressions.Expression{"go": " fromJSON(needs.setup.outputs.go) "},
+			}}},
+		},
+		{
+			name:     "matrix expression",
+			doc:      `{"strategy":{"matrix":"${{ fromJSON(needs.setup.outputs.matrix) }}"}}`,
+			expected: Job{Strategy: Strategy{Matrix: &Matrix{Expression: " fromJSON(needs.setup.outputs.matrix) "}}},
+		},
+		{
+			name:     "env numbers and booleans",
+			doc:      `{"env":{"RETRIES":3,"RATIO":1.10,"DEBUG":false,"EMPTY":null}}`,
+			expected: Job{Env: map[string]string{"RETRIES": "3", "RATIO": "1.10", "DEBUG": "false", "EMPTY": ""}},
+		},
+		{
+			name:     "with scalars",
+			doc:      `{"with":{"dry-run":true,"retries":2,"target":"all"}}`,
+			expected: Job{With: map[string]string{"dry-run": "true", "retries": "2", "target": "all"}},
+		},
+		{
+			name:     "permissions shorthand",
+			doc:      `{"permissions":"read-all"}`,
+			expected: Job{Permissions: Permissions{All: AccessLevelRead}},
+		},
+		{
+			name:     "container shorthand",
+			doc:      `{"container":"node:18"}`,
+			expected: Job{Container: Container{Image: "node:18"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var j Job
+			require.NoError(t, json.Unmarshal([]byte(tc.doc), &j))
+			assert.Equal(t, tc.expected, j)
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package runner
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// parseEnvFile reads a GITHUB_OUTPUT or GITHUB_ENV file,
+// which holds name=value lines and name<<DELIMITER multiline values
+// https://docs.github.com/en/actions/reference/workflow-commands-for-github-actions#multiline-strings
+func parseEnvFile(filename string) (map[string]string, error) {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	vals := make(map[string]string)
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	sc.Buffer(nil, len(b)+1)
+	for sc.Scan() {
+		line := sc.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		eq := strings.Index(line, "=")
+		heredoc := strings.Index(line, "<<")
+		if eq >= 0 && (heredoc < 0 || eq < heredoc) {
+			vals[line[:eq]] = line[eq+1:]
+			continue
+		}
+
+		if heredoc < 0 {
+			return nil, fmt.Errorf("%s: invalid line %q, expected name=value or name<<DELIMITER", filename, line)
+		}
+
+		name, delim := line[:heredoc], line[heredoc+2:]
+		var value []string
+		closed := false
+		for sc.Scan() {
+			if sc.Text() == delim {
+				closed = true
+				break
+			}
+			value = append(value, sc.Text())
+		}
+
+		if !closed {
+			return nil, fmt.Errorf("%s: missing delimiter %q for %s", filename, delim, name)
+		}
+
+		vals[name] = strings.Join(value, "\n")
+	}
+
+	return vals, sc.Err()
+}
+
+// parsePathFile reads a GITHUB_PATH file, one directory per line
+func parsePathFile(filename string) ([]string, error) {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var dirs []string
+	for line := range strings.Lines(string(b)) {
+		if line = strings.TrimSpace(line); line != "" {
+			dirs = append(dirs, line)
+		}
+	}
+
+	return dirs, nil
+}
@@ -0,0 +1,601 @@
+// Package runner runs a job on the local machine, emulating the GitHub runner closely enough
+// to iterate on run steps without pushing.
+//
+// Run steps are executed with bash in a clean environment holding only the variables the runner
+// would set, and the GITHUB_OUTPUT, GITHUB_ENV, GITHUB_PATH and GITHUB_STEP_SUMMARY files are
+// handled between steps. Uses steps are replaced by an ActionStub registered for the action, or skipped.
+package runner
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cakehappens/gocto"
+	"github.com/cakehappens/gocto/expressions"
+	"github.com/cakehappens/gocto/internal/util"
+	"github.com/cakehappens/gocto/plan"
+)
+
+// minute is the unit of TimeoutMinutes, tests shorten it
+var minute = time.Minute
+
+// Runner runs jobs locally, the zero value is not usable, use New
+type Runner struct {
+	// Workspace is the directory steps run in, GITHUB_WORKSPACE, it defaults to the current directory
+	Workspace string
+	// Event is the event the job runs for, it fills the github and inputs contexts
+	Event plan.Event
+	// Matrix selects the combination of a matrix job, the first combination with these values runs
+	Matrix  map[string]string
+	Secrets map[string]string
+	Vars    map[string]string
+	// Env is the environment of the runner machine, it defaults to PATH and HOME of the current process
+	Env    map[string]string
+	Stdout io.Writer
+	Stderr io.Writer
+
+	stubs map[string]ActionStub
+}
+
+// New returns a Runner writing to the standard streams, with the built-in stubs
+// for checkout, cache and artifacts, overridden by the stubs passed to RegisterStub
+func New() *Runner {
+	stubs := builtinStubs()
+	maps.Copy(stubs, registeredStubs())
+
+	return &Runner{
+		Event: plan.Event{
+			Name: "push",
+			Ref:  "refs/heads/main",
+		},
+		Env: map[string]string{
+			"PATH": os.Getenv("PATH"),
+			"HOME": os.Getenv("HOME"),
+		},
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+		stubs:  stubs,
+	}
+}
+
+// JobResult is the outcome of a local run of a job
+type JobResult struct {
+	Job    string
+	Matrix map[string]any
+	// Result is success, failure or cancelled
+	Result  expressions.Status
+	Outputs map[string]string
+	Steps   []StepResult
+	// Summary is everything the steps wrote to GITHUB_STEP_SUMMARY
+	Summary string
+}
+
+type StepResult struct {
+	Index int
+	ID    string
+	Name  string
+	// Outcome is the result before continue-on-error is applied, Conclusion after
+	Outcome    expressions.Status
+	Conclusion expressions.Status
+	Outputs    map[string]string
+	// Err explains a failure, or why a uses step was skipped
+	Err error
+}
+
+// job holds the state of a single run
+type job struct {
+	*Runner
+	workflow gocto.Workflow
+	id       string
+	job      gocto.Job
+	// workspace is the absolute Workspace
+	workspace string
+	temp      string
+	contexts  map[string]any
+	env       map[string]string
+	// githubEnv and githubPath collect what steps wrote to GITHUB_ENV and GITHUB_PATH
+	githubEnv  map[string]string
+	githubPath []string
+	steps      map[string]any
+	summary    strings.Builder
+	// posts are the Post calls of the stubs which ran, in order
+	posts []func(ctx context.Context, status expressions.Status) error
+}
+
+// RunJob runs a job of w. The error is only set when the job can't run at all,
+// failing steps are reported in the result.
+func (r *Runner) RunJob(ctx context.Context, w gocto.Workflow, jobID string) (*JobResult, error) {
+	j, ok := w.Jobs[jobID]
+	if !ok {
+		return nil, fmt.Errorf("unknown job %q, expected one of %s", jobID, strings.Join(slices.Sorted(maps.Keys(w.Jobs)), ", "))
+	}
+
+	if j.Uses != "" {
+		return nil, fmt.Errorf("job %q calls the reusable workflow %s, which can't run locally", jobID, j.Uses)
+	}
+
+	matrix, err := r.selectMatrix(j)
+	if err != nil {
+		return nil, fmt.Errorf("job %q: %w", jobID, err)
+	}
+
+	workspace, err := filepath.Abs(cmp.Or(r.Workspace, "."))
+	if err != nil {
+		return nil, err
+	}
+
+	temp, err := os.MkdirTemp("", "gocto-run-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(temp)
+
+	payload, err := json.Marshal(r.Event.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("event payload: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(temp, "event.json"), payload, 0o644); err != nil {
+		return nil, err
+	}
+
+	run := &job{
+		Runner:    r,
+		workflow:  w,
+		id:        jobID,
+		job:       j,
+		workspace: workspace,
+		temp:      temp,
+		githubEnv: make(map[string]string),
+		steps:     make(map[string]any),
+	}
+
+	run.contexts = map[string]any{
+		"github":  r.Event.GitHubContext(w),
+		"inputs":  r.Event.InputsContext(w),
+		"matrix":  util.OrEmpty(matrix),
+		"secrets": stringsToAny(r.Secrets),
+		"vars":    stringsToAny(r.Vars),
+		"needs":   map[string]any{},
+		"steps":   run.steps,
+		"runner": map[string]any{
+			"os":   runnerOS(),
+			"arch": runnerArch(),
+			"temp": temp,
+		},
+	}
+
+	run.env = make(map[string]string)
+	for _, layer := range []map[string]string{w.Env, j.Env} {
+		if err := run.interpolateEnv(run.env, layer); err != nil {
+			return nil, fmt.Errorf("job %q: env: %w", jobID, err)
+		}
+	}
+
+	timeout, err := j.TimeoutMinutes.Resolve(run.evaluator(expressions.StatusSuccess, run.env))
+	if err != nil {
+		return nil, fmt.Errorf("job %q: timeout-minutes: %w", jobID, err)
+	}
+
+	jobCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		jobCtx, cancel = context.WithTimeout(ctx, time.Duration(timeout)*minute)
+		defer cancel()
+	}
+
+	res := &JobResult{
+		Job:    jobID,
+		Matrix: matrix,
+		Result: expressions.StatusSuccess,
+	}
+
+	for i, s := range j.Steps {
+		// steps running after the job timed out, e.g. if: always(), still get to run
+		stepCtx := jobCtx
+		if jobCtx.Err() != nil {
+			res.Result = expressions.StatusCancelled
+			stepCtx = ctx
+		}
+
+		sr := run.step(stepCtx, i, s, res.Result)
+		res.Steps = append(res.Steps, sr)
+
+		if s.ID != "" {
+			run.steps[s.ID] = map[string]any{
+				"outcome":    string(sr.Outcome),
+				"conclusion": string(sr.Conclusion),
+				"outputs":    stringsToAny(sr.Outputs),
+			}
+		}
+
+		switch {
+		case jobCtx.Err() != nil:
+			res.Result = expressions.StatusCancelled
+		case sr.Conclusion == expressions.StatusFailure && res.Result == expressions.StatusSuccess:
+			res.Result = expressions.StatusFailure
+		}
+	}
+
+	// post steps default to post-if: always(), so they run after failures too
+	for _, post := range slices.Backward(run.posts) {
+		if err := post(ctx, res.Result); err != nil {
+			fmt.Fprintf(run.Stderr, "post step failed: %v\n", err)
+			if res.Result == expressions.StatusSuccess {
+				res.Result = expressions.StatusFailure
+			}
+		}
+	}
+
+	res.Summary = run.summary.String()
+	res.Outputs = make(map[string]string)
+	ev := run.evaluator(res.Result, run.env)
+	for name, value := range j.Outputs {
+		out, err := ev.Interpolate(value)
+		if err != nil {
+			return res, fmt.Errorf("job %q: Outputs[%q]: %w", jobID, name, err)
+		}
+		res.Outputs[name] = out
+	}
+
+	return res, nil
+}
+
+// selectMatrix returns the first combination of the job matrix holding every value of r.Matrix
+func (r *Runner) selectMatrix(j gocto.Job) (map[string]any, error) {
+	combos := j.Strategy.Matrix.Combinations()
+	if len(combos) == 0 {
+		if len(r.Matrix) > 0 {
+			return nil, errors.New("matrix values were given, but the job has no matrix")
+		}
+		return nil, nil
+	}
+
+	for _, combo := range combos {
+		matches := true
+		for k, v := range r.Matrix {
+			if expressions.ToString(combo[k]) != v {
+				matches = false
+				break
+			}
+		}
+
+		if matches {
+			return combo, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no matrix combination matches %v", r.Matrix)
+}
+
+func (run *job) evaluator(status expressions.Status, env map[string]string) *expressions.Evaluator {
+	contexts := maps.Clone(run.contexts)
+	contexts["env"] = stringsToAny(env)
+	contexts["job"] = map[string]any{"status": string(status)}
+
+	return &expressions.Evaluator{
+		Contexts: contexts,
+		Status:   status,
+	}
+}
+
+// interpolateEnv evaluates the values of layer, which can refer to the env set by the layers before, into env
+func (run *job) interpolateEnv(env map[string]string, layer map[string]string) error {
+	ev := run.evaluator(expressions.StatusSuccess, env)
+	for _, k := range slices.Sorted(maps.Keys(layer)) {
+		v, err := ev.Interpolate(layer[k])
+		if err != nil {
+			return fmt.Errorf("%s: %w", k, err)
+		}
+		env[k] = v
+	}
+
+	return nil
+}
+
+func (run *job) step(ctx context.Context, i int, s gocto.Step, status expressions.Status) StepResult {
+	sr := StepResult{
+		Index:   i,
+		ID:      s.ID,
+		Name:    s.DisplayName(),
+		Outputs: map[string]string{},
+	}
+
+	fail := func(err error) StepResult {
+		sr.Err = err
+		sr.Outcome = expressions.StatusFailure
+		sr.Conclusion = expressions.StatusFailure
+		if ok, err := s.ContinueOnError.Resolve(run.evaluator(status, run.env), false); err == nil && ok {
+			sr.Conclusion = expressions.StatusSuccess
+		}
+		fmt.Fprintf(run.Stderr, "step %d %s failed: %v\n", i+1, sr.Name, err)
+		return sr
+	}
+
+	env := maps.Clone(run.env)
+	maps.Copy(env, run.githubEnv)
+	if err := run.interpolateEnv(env, s.Env); err != nil {
+		return fail(fmt.Errorf("env: %w", err))
+	}
+
+	ev := run.evaluator(status, env)
+	ok, err := ev.EvaluateCondition(s.If)
+	if err != nil {
+		return fail(fmt.Errorf("if: %w", err))
+	}
+
+	if !ok {
+		sr.Outcome = expressions.StatusSkipped
+		sr.Conclusion = expressions.StatusSkipped
+		fmt.Fprintf(run.Stdout, "--- skip %d. %s\n", i+1, sr.Name)
+		return sr
+	}
+
+	fmt.Fprintf(run.Stdout, "--- run  %d. %s\n", i+1, sr.Name)
+
+	timeout, err := s.TimeoutMinutes.Resolve(ev)
+	if err != nil {
+		return fail(fmt.Errorf("timeout-minutes: %w", err))
+	}
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(timeout)*minute)
+		defer cancel()
+	}
+
+	if s.Uses != "" {
+		err = run.uses(ctx, i, s, ev, env, &sr)
+	} else {
+		err = run.script(ctx, i, s, ev, env, &sr)
+	}
+
+	if ctx.Err() == context.DeadlineExceeded && timeout > 0 {
+		err = fmt.Errorf("timed out after %d minutes", timeout)
+	}
+
+	if err != nil {
+		return fail(err)
+	}
+
+	sr.Outcome = expressions.StatusSuccess
+	sr.Conclusion = expressions.StatusSuccess
+	return sr
+}
+
+func (run *job) uses(ctx context.Context, i int, s gocto.Step, ev *expressions.Evaluator, env map[string]string, sr *StepResult) error {
+	stub, ok := run.stubFor(s.Uses)
+	if !ok {
+		sr.Err = fmt.Errorf("no stub registered for %s, skipped", s.Uses)
+		fmt.Fprintf(run.Stdout, "%v\n", sr.Err)
+		return nil
+	}
+
+	// a stub running an action program, e.g. with toolkit.SaveState, saves the state for its post in GITHUB_STATE
+	stateFile := filepath.Join(run.temp, fmt.Sprintf("step-%d-state", i+1))
+	if err := os.WriteFile(stateFile, nil, 0o644); err != nil {
+		return err
+	}
+
+	env = maps.Clone(env)
+	env["GITHUB_STATE"] = stateFile
+
+	call := &StubCall{
+		Uses:      s.Uses,
+		With:      make(map[string]string, len(s.With)),
+		Env:       env,
+		Workspace: run.workspace,
+		Stdout:    run.Stdout,
+	}
+
+	for k, v := range s.With {
+		val, err := ev.Interpolate(expressions.ToString(v))
+		if err != nil {
+			return fmt.Errorf("with %s: %w", k, err)
+		}
+		call.With[k] = val
+	}
+
+	res, err := stub.Run(ctx, call)
+	if err != nil {
+		return err
+	}
+
+	if res == nil {
+		return nil
+	}
+
+	maps.Copy(sr.Outputs, res.Outputs)
+	maps.Copy(run.githubEnv, res.Env)
+
+	if post, ok := stub.(PostStub); ok {
+		state, err := parseEnvFile(stateFile)
+		if err != nil {
+			return err
+		}
+		maps.Copy(state, res.State)
+
+		run.posts = append(run.posts, func(ctx context.Context, status expressions.Status) error {
+			call.JobStatus = status
+			call.State = state
+			for name, value := range state {
+				call.Env["STATE_"+name] = value
+			}
+			return post.Post(ctx, call)
+		})
+	}
+
+	if res.ExitCode != 0 {
+		return fmt.Errorf("exit code %d", res.ExitCode)
+	}
+
+	return nil
+}
+
+func (run *job) script(ctx context.Context, i int, s gocto.Step, ev *expressions.Evaluator, env map[string]string, sr *StepResult) error {
+	shell, _ := run.workflow.StepShell(run.id, i)
+	if shell.WindowsOnly() && runtime.GOOS != "windows" {
+		return fmt.Errorf("shell %q is only available on Windows", shell)
+	}
+
+	script, err := ev.Interpolate(s.Run)
+	if err != nil {
+		return fmt.Errorf("run: %w", err)
+	}
+
+	dir, err := ev.Interpolate(cmp.Or(s.WorkingDirectory, run.job.Defaults.Run.WorkingDirectory, run.workflow.Defaults.Run.WorkingDirectory))
+	if err != nil {
+		return fmt.Errorf("working-directory: %w", err)
+	}
+
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(run.workspace, dir)
+	}
+
+	prefix := filepath.Join(run.temp, fmt.Sprintf("step-%d", i+1))
+	files := map[string]string{
+		"GITHUB_OUTPUT":       prefix + "-output",
+		"GITHUB_ENV":          prefix + "-env",
+		"GITHUB_PATH":         prefix + "-path",
+		"GITHUB_STEP_SUMMARY": prefix + "-summary",
+		// run steps have no post step, GitHub discards their state too
+		"GITHUB_STATE": prefix + "-state",
+	}
+
+	for _, f := range files {
+		if err := os.WriteFile(f, nil, 0o644); err != nil {
+			return err
+		}
+	}
+
+	// https://docs.github.com/en/actions/reference/workflow-syntax-for-github-actions#defaultsrunshell
+	scriptPath := prefix + cmp.Or(shell.Extension(), ".sh")
+	args, err := shell.CommandLine(scriptPath)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(scriptPath, []byte(script), 0o644); err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = dir
+	cmd.Env = run.environ(env, files)
+	cmd.Stdout = run.Stdout
+	cmd.Stderr = run.Stderr
+	cmd.WaitDelay = time.Second
+	runErr := cmd.Run()
+
+	// the files are processed even when the step failed, like the runner does
+	outputs, err := parseEnvFile(files["GITHUB_OUTPUT"])
+	if err != nil {
+		return err
+	}
+	maps.Copy(sr.Outputs, outputs)
+
+	setEnv, err := parseEnvFile(files["GITHUB_ENV"])
+	if err != nil {
+		return err
+	}
+	maps.Copy(run.githubEnv, setEnv)
+
+	path, err := parsePathFile(files["GITHUB_PATH"])
+	if err != nil {
+		return err
+	}
+	run.githubPath = append(run.githubPath, path...)
+
+	summary, err := os.ReadFile(files["GITHUB_STEP_SUMMARY"])
+	if err != nil {
+		return err
+	}
+	run.summary.Write(summary)
+
+	return runErr
+}
+
+// environ layers the default variables of the runner, the env of the step and the step files over the machine env
+// https://docs.github.com/en/actions/reference/variables-reference#default-environment-variables
+func (run *job) environ(env map[string]string, files map[string]string) []string {
+	github := run.contexts["github"].(map[string]any)
+
+	vars := maps.Clone(run.Env)
+	maps.Copy(vars, map[string]string{
+		"CI":                "true",
+		"GITHUB_ACTIONS":    "true",
+		"GITHUB_WORKSPACE":  run.workspace,
+		"GITHUB_WORKFLOW":   run.workflow.Name,
+		"GITHUB_JOB":        run.id,
+		"GITHUB_EVENT_NAME": run.Event.Name,
+		"GITHUB_EVENT_PATH": filepath.Join(run.temp, "event.json"),
+		"GITHUB_REF":        run.Event.Ref,
+		"GITHUB_REF_NAME":   expressions.ToString(github["ref_name"]),
+		"GITHUB_REF_TYPE":   expressions.ToString(github["ref_type"]),
+		"GITHUB_BASE_REF":   expressions.ToString(github["base_ref"]),
+		"GITHUB_HEAD_REF":   expressions.ToString(github["head_ref"]),
+		"GITHUB_REPOSITORY": expressions.ToString(github["repository"]),
+		"RUNNER_OS":         runnerOS(),
+		"RUNNER_ARCH":       runnerArch(),
+		"RUNNER_TEMP":       run.temp,
+	})
+	maps.Copy(vars, env)
+	maps.Copy(vars, files)
+
+	// directories added to GITHUB_PATH later come first
+	path := slices.Clone(run.githubPath)
+	slices.Reverse(path)
+	if vars["PATH"] != "" {
+		path = append(path, vars["PATH"])
+	}
+	vars["PATH"] = strings.Join(path, string(os.PathListSeparator))
+
+	environ := make([]string, 0, len(vars))
+	for _, k := range slices.Sorted(maps.Keys(vars)) {
+		environ = append(environ, k+"="+vars[k])
+	}
+
+	return environ
+}
+
+func stringsToAny(m map[string]string) map[string]any {
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+func runnerOS() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return "macOS"
+	case "windows":
+		return "Windows"
+	}
+	return "Linux"
+}
+
+func runnerArch() string {
+	switch runtime.GOARCH {
+	case "arm64":
+		return "ARM64"
+	case "arm":
+		return "ARM"
+	case "386":
+		return "X86"
+	}
+	return "X64"
+}
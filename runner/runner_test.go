@@ -0,0 +1,306 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cakehappens/gocto"
+	"github.com/cakehappens/gocto/expressions"
+)
+
+func testRunner(t *testing.T) (*Runner, *bytes.Buffer) {
+	t.Helper()
+
+	var out bytes.Buffer
+	r := New()
+	r.Workspace = t.TempDir()
+	r.Stdout = &out
+	r.Stderr = &out
+
+	t.Cleanup(func() { t.Log(out.String()) })
+	return r, &out
+}
+
+func TestRunJob(t *testing.T) {
+	r, out := testRunner(t)
+	require.NoError(t, os.Mkdir(filepath.Join(r.Workspace, "sub"), 0o755))
+
+	r.Stub("actions/checkout", StubFunc(func(ctx context.Context, call *StubCall) (*StubResult, error) {
+		return &StubResult{Outputs: map[string]string{"ref": call.With["ref"]}}, nil
+	}))
+
+	w := gocto.Workflow{
+		Name: "CI",
+		Env:  map[string]string{"LEVEL": "workflow", "WORKFLOW": "yes"},
+		Defaults: gocto.Defaults{
+			Run: gocto.DefaultsRun{WorkingDirectory: "sub"},
+		},
+		Jobs: map[string]gocto.Job{
+			"build": {
+				Env: map[string]string{"LEVEL": "job", "REF": "${{ github.ref_name }}"},
+				Outputs: map[string]string{
+					"greeting": "${{ steps.hello.outputs.greeting }}",
+				},
+				Steps: []gocto.Step{
+					{ID: "checkout", Uses: "actions/checkout@v4", With: map[string]any{"ref": "${{ github.ref }}"}},
+					{Uses: "actions/setup-go@v5"},
+					{
+						ID:  "hello",
+						Env: map[string]string{"LEVEL": "step"},
+						Run: `echo "greeting=hello $LEVEL $WORKFLOW $REF" >> "$GITHUB_OUTPUT"
+echo "FROM_ENV=set" >> "$GITHUB_ENV"
+mkdir -p bin && printf '#!/bin/sh\necho tool ran\n' > bin/tool && chmod +x bin/tool
+echo "$PWD/bin" >> "$GITHUB_PATH"
+{ echo 'multi<<EOF'; echo a; echo b; echo EOF; } >> "$GITHUB_OUTPUT"
+echo "# Summary" >> "$GITHUB_STEP_SUMMARY"
+basename "$PWD"`,
+					},
+					{Run: `test "$FROM_ENV" = set && tool && echo "${{ steps.checkout.outputs.ref }}"`},
+					{ID: "flaky", ContinueOnError: gocto.BoolValue(true), Run: "exit 3"},
+					{If: "steps.flaky.outcome == 'failure'", Run: "echo flaky failed"},
+					{Name: "unreachable", If: "failure()", Run: "echo not reached"},
+				},
+			},
+		},
+	}
+
+	res, err := r.RunJob(context.Background(), w, "build")
+	require.NoError(t, err)
+
+	assert.Equal(t, expressions.StatusSuccess, res.Result)
+	assert.Equal(t, map[string]string{"greeting": "hello step yes main"}, res.Outputs)
+	assert.Equal(t, "# Summary\n", res.Summary)
+
+	require.Len(t, res.Steps, 7)
+	assert.Equal(t, map[string]string{"ref": "refs/heads/main"}, res.Steps[0].Outputs)
+	assert.EqualError(t, res.Steps[1].Err, "no stub registered for actions/setup-go@v5, skipped")
+	assert.Equal(t, "a\nb", res.Steps[2].Outputs["multi"])
+	assert.Equal(t, expressions.StatusSuccess, res.Steps[3].Conclusion)
+	assert.Equal(t, expressions.StatusFailure, res.Steps[4].Outcome)
+	assert.Equal(t, expressions.StatusSuccess, res.Steps[4].Conclusion)
+	assert.Equal(t, expressions.StatusSuccess, res.Steps[5].Conclusion)
+	assert.Equal(t, expressions.StatusSkipped, res.Steps[6].Conclusion)
+
+	assert.Contains(t, out.String(), "sub\n")
+	assert.Contains(t, out.String(), "tool ran\nrefs/heads/main\n")
+	assert.Contains(t, out.String(), "flaky failed\n")
+	assert.NotContains(t, out.String(), "not reached")
+}
+
+func TestRunJobFailure(t *testing.T) {
+	r, out := testRunner(t)
+
+	w := gocto.Workflow{
+		Name: "CI",
+		Jobs: map[string]gocto.Job{
+			"test": {
+				Strategy: gocto.Strategy{
+					Matrix: &gocto.Matrix{
+						Map: map[string][]any{
+							"go": {1, 2},
+						},
+					},
+				},
+				Steps: []gocto.Step{
+					{Name: "test", Run: "echo go ${{ matrix.go }}; false; echo not reached"},
+					{Name: "unreachable", Run: "echo not reached"},
+					{If: "failure()", Run: "echo cleanup"},
+				},
+			},
+		},
+	}
+
+	r.Matrix = map[string]string{"go": "2"}
+	res, err := r.RunJob(context.Background(), w, "test")
+	require.NoError(t, err)
+
+	assert.Equal(t, expressions.StatusFailure, res.Result)
+	assert.Equal(t, map[string]any{"go": 2}, res.Matrix)
+	assert.Equal(t, []expressions.Status{
+		expressions.StatusFailure,
+		expressions.StatusSkipped,
+		expressions.StatusSuccess,
+	}, []expressions.Status{res.Steps[0].Conclusion, res.Steps[1].Conclusion, res.Steps[2].Conclusion})
+	assert.Contains(t, out.String(), "go 2\n")
+	assert.Contains(t, out.String(), "cleanup\n")
+	assert.NotContains(t, out.String(), "not reached")
+
+	r.Matrix = map[string]string{"go": "3"}
+	_, err = r.RunJob(context.Background(), w, "test")
+	assert.EqualError(t, err, `job "test": no matrix combination matches map[go:3]`)
+}
+
+type recordingPost struct {
+	StubFunc
+	statuses []expressions.Status
+	states   []map[string]string
+}
+
+func (p *recordingPost) Post(ctx context.Context, call *StubCall) error {
+	p.statuses = append(p.statuses, call.JobStatus)
+	p.states = append(p.states, map[string]string{"state": call.State["pid"], "env": call.Env["STATE_pid"], "file": call.State["file"]})
+	return nil
+}
+
+func TestRunJobPostState(t *testing.T) {
+	r, _ := testRunner(t)
+
+	post := &recordingPost{StubFunc: func(ctx context.Context, call *StubCall) (*StubResult, error) {
+		// an action program saves its state in GITHUB_STATE
+		if err := os.WriteFile(call.Env["GITHUB_STATE"], []byte("file=saved\n"), 0o644); err != nil {
+			return nil, err
+		}
+		return &StubResult{State: map[string]string{"pid": "42"}}, nil
+	}}
+	r.Stub("example/post", post)
+
+	w := gocto.Workflow{
+		Name: "CI",
+		Jobs: map[string]gocto.Job{
+			"test": {
+				Steps: []gocto.Step{{Uses: "example/post@v1"}},
+			},
+		},
+	}
+
+	res, err := r.RunJob(context.Background(), w, "test")
+	require.NoError(t, err)
+	assert.Equal(t, expressions.StatusSuccess, res.Result)
+	assert.Equal(t, []map[string]string{{"state": "42", "env": "42", "file": "saved"}}, post.states)
+}
+
+func TestRunJobPostAfterFailure(t *testing.T) {
+	r, _ := testRunner(t)
+
+	post := &recordingPost{StubFunc: func(ctx context.Context, call *StubCall) (*StubResult, error) {
+		return &StubResult{}, nil
+	}}
+	r.Stub("example/post", post)
+	cache := filepath.Join(t.TempDir(), "cache")
+	r.Stub("actions/cache", &CacheStub{Dir: cache})
+
+	w := gocto.Workflow{
+		Name: "CI",
+		Jobs: map[string]gocto.Job{
+			"test": {
+				Steps: []gocto.Step{
+					{Uses: "example/post@v1"},
+					{Uses: "actions/cache@v4", With: map[string]any{"path": ".cache", "key": "deps"}},
+					{Run: "mkdir .cache && false"},
+				},
+			},
+		},
+	}
+
+	res, err := r.RunJob(context.Background(), w, "test")
+	require.NoError(t, err)
+	assert.Equal(t, expressions.StatusFailure, res.Result)
+	assert.Equal(t, []expressions.Status{expressions.StatusFailure}, post.statuses)
+	assert.NoDirExists(t, filepath.Join(cache, "deps"), "actions/cache only saves after a successful job")
+}
+
+func TestRunJobTimeout(t *testing.T) {
+	minute = 50 * time.Millisecond
+	t.Cleanup(func() { minute = time.Minute })
+
+	r, out := testRunner(t)
+
+	w := gocto.Workflow{
+		Name: "CI",
+		Jobs: map[string]gocto.Job{
+			"slow": {
+				TimeoutMinutes: gocto.IntValue(30),
+				Strategy: gocto.Strategy{
+					Matrix: &gocto.Matrix{
+						Include: []map[string]any{{"timeout": 1, "experimental": true}},
+					},
+				},
+				Steps: []gocto.Step{
+					{
+						TimeoutMinutes:  gocto.IntExpression("matrix.timeout"),
+						ContinueOnError: gocto.BoolExpression("matrix.experimental"),
+						Run:             "sleep 5",
+					},
+					{Run: "sleep 5"},
+					{Name: "unreachable", Run: "echo not reached"},
+					{If: "always()", Run: "echo always"},
+				},
+			},
+		},
+	}
+
+	res, err := r.RunJob(context.Background(), w, "slow")
+	require.NoError(t, err)
+
+	assert.Equal(t, expressions.StatusCancelled, res.Result)
+	assert.EqualError(t, res.Steps[0].Err, "timed out after 1 minutes")
+	assert.Equal(t, expressions.StatusSuccess, res.Steps[0].Conclusion)
+	assert.Equal(t, expressions.StatusFailure, res.Steps[1].Conclusion)
+	assert.Equal(t, expressions.StatusSkipped, res.Steps[2].Conclusion)
+	assert.Equal(t, expressions.StatusSuccess, res.Steps[3].Conclusion)
+	assert.Contains(t, out.String(), "always\n")
+}
+
+func TestRunJobShells(t *testing.T) {
+	r, out := testRunner(t)
+
+	w := gocto.Workflow{
+		Name:     "shells",
+		Defaults: gocto.Defaults{Run: gocto.DefaultsRun{Shell: gocto.ShellSh}},
+		Jobs: map[string]gocto.Job{
+			"build": {
+				Steps: []gocto.Step{
+					{Run: `echo "sh $0"`},
+					{Shell: gocto.CustomShell("sh -u {0} custom"), Run: `echo "template $1"`},
+					{Shell: gocto.ShellCmd, Run: "echo never"},
+				},
+			},
+		},
+	}
+
+	res, err := r.RunJob(context.Background(), w, "build")
+	require.NoError(t, err)
+	assert.Equal(t, expressions.StatusSuccess, res.Steps[0].Conclusion)
+	assert.Equal(t, expressions.StatusSuccess, res.Steps[1].Conclusion)
+	assert.EqualError(t, res.Steps[2].Err, `shell "cmd" is only available on Windows`)
+	assert.Contains(t, out.String(), "sh ")
+	assert.Contains(t, out.String(), "template custom")
+}
+
+func TestRunGoStep(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a Go program")
+	}
+
+	r, out := testRunner(t)
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	r.Workspace = wd
+
+	w := gocto.Workflow{
+		Name: "go",
+		Jobs: map[string]gocto.Job{
+			"greet": {
+				Steps: []gocto.Step{
+					gocto.GoRunStep("./testdata/greet", map[string]expressions.Expression{"name": expressions.From("github.ref_name")}),
+					gocto.GoRunStep("./testdata/greet", nil),
+				},
+			},
+		},
+	}
+
+	res, err := r.RunJob(context.Background(), w, "greet")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"greeting": "hello main"}, res.Steps[0].Outputs)
+	assert.Equal(t, expressions.StatusFailure, res.Steps[1].Outcome)
+	assert.Equal(t, "# Greeted main\n", res.Summary)
+	assert.Contains(t, out.String(), "hello main\n")
+	assert.Contains(t, out.String(), "::error::input required and not supplied: name\n")
+}
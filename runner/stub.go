@@ -0,0 +1,114 @@
+package runner
+
+import (
+	"context"
+	"io"
+	"maps"
+	"strings"
+	"sync"
+
+	"github.com/cakehappens/gocto/expressions"
+)
+
+// ActionStub stands in for the action of a uses: step during a local run
+type ActionStub interface {
+	Run(ctx context.Context, call *StubCall) (*StubResult, error)
+}
+
+// PostStub is implemented by stubs which, like actions/cache, also do something at the end of the job.
+// Post runs in reverse order of the steps, like post-if: always() even when the job failed.
+type PostStub interface {
+	ActionStub
+	Post(ctx context.Context, call *StubCall) error
+}
+
+// StubCall is a single uses: step
+type StubCall struct {
+	Uses string
+	// With are the evaluated with: inputs
+	With map[string]string
+	// Env is the environment of the step
+	Env       map[string]string
+	Workspace string
+	Stdout    io.Writer
+	// JobStatus is the result of the job so far when Post runs
+	JobStatus expressions.Status
+	// State is what Run saved for Post, in StubResult.State or GITHUB_STATE.
+	// Post also gets it in Env as STATE_<name>, which toolkit.GetState reads.
+	State map[string]string
+}
+
+// Lines returns the non empty lines of a multiline input, e.g. path
+func (c *StubCall) Lines(input string) []string {
+	var lines []string
+	for line := range strings.Lines(c.With[input]) {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// StubResult is what a stub reports back
+type StubResult struct {
+	Outputs map[string]string
+	// Env is set for the following steps, like writing to GITHUB_ENV
+	Env map[string]string
+	// State is saved for Post, like writing to GITHUB_STATE
+	State map[string]string
+	// ExitCode fails the step when it is not 0
+	ExitCode int
+}
+
+// StubFunc adapts a function to an ActionStub
+type StubFunc func(ctx context.Context, call *StubCall) (*StubResult, error)
+
+func (f StubFunc) Run(ctx context.Context, call *StubCall) (*StubResult, error) {
+	return f(ctx, call)
+}
+
+var registry struct {
+	mu    sync.Mutex
+	stubs map[string]ActionStub
+}
+
+// RegisterStub registers a stub for every Runner created by New afterwards.
+// It is meant to be called from an init function next to gocto.Register,
+// see Runner.Stub for how uses is matched.
+func RegisterStub(uses string, stub ActionStub) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if registry.stubs == nil {
+		registry.stubs = make(map[string]ActionStub)
+	}
+	registry.stubs[uses] = stub
+}
+
+func registeredStubs() map[string]ActionStub {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	return maps.Clone(registry.stubs)
+}
+
+// Stub registers a stub for the steps using uses. uses is either a full reference,
+// e.g. actions/checkout@v4, or a reference without the @ref, which stubs every version.
+func (r *Runner) Stub(uses string, stub ActionStub) *Runner {
+	if r.stubs == nil {
+		r.stubs = make(map[string]ActionStub)
+	}
+	r.stubs[uses] = stub
+
+	return r
+}
+
+func (r *Runner) stubFor(uses string) (ActionStub, bool) {
+	if stub, ok := r.stubs[uses]; ok {
+		return stub, true
+	}
+
+	name, _, _ := strings.Cut(uses, "@")
+	stub, ok := r.stubs[name]
+	return stub, ok
+}
@@ -0,0 +1,374 @@
+package runner
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cakehappens/gocto/expressions"
+)
+
+// stateDir holds the caches and artifacts of the built-in stubs between runs
+func stateDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+
+	return filepath.Join(dir, "gocto")
+}
+
+// builtinStubs stand in for the actions most jobs can't do without
+func builtinStubs() map[string]ActionStub {
+	artifacts := &ArtifactStore{Dir: filepath.Join(stateDir(), "artifacts")}
+
+	return map[string]ActionStub{
+		"actions/checkout":          &CheckoutStub{},
+		"actions/cache":             &CacheStub{Dir: filepath.Join(stateDir(), "cache")},
+		"actions/upload-artifact":   artifacts.Upload(),
+		"actions/download-artifact": artifacts.Download(),
+	}
+}
+
+// CheckoutStub stands in for actions/checkout by copying the working tree of Source,
+// uncommitted changes included and .git excluded, to the path input
+type CheckoutStub struct {
+	// Source defaults to the current directory
+	Source string
+}
+
+func (s *CheckoutStub) Run(ctx context.Context, call *StubCall) (*StubResult, error) {
+	src, err := filepath.Abs(cmp.Or(s.Source, "."))
+	if err != nil {
+		return nil, err
+	}
+
+	dst := resolvePath(call.Workspace, call.With["path"])
+	res := &StubResult{
+		Outputs: map[string]string{"ref": call.With["ref"]},
+	}
+
+	if src == dst {
+		return res, nil
+	}
+
+	return res, copyTree(src, dst, func(path string) bool {
+		return path == filepath.Join(src, ".git") || path == dst
+	})
+}
+
+// CacheStub stands in for actions/cache with a directory per key in Dir.
+// Like the real cache, an entry is saved at the end of a successful job and never overwritten.
+type CacheStub struct {
+	Dir string
+}
+
+func (s *CacheStub) entry(key string) string {
+	return filepath.Join(s.Dir, url.PathEscape(key))
+}
+
+func (s *CacheStub) Run(ctx context.Context, call *StubCall) (*StubResult, error) {
+	key := call.With["key"]
+	if key == "" {
+		return nil, errors.New("input key is required")
+	}
+
+	res := &StubResult{
+		Outputs: map[string]string{"cache-hit": "false"},
+	}
+
+	restored := s.entry(key)
+	if exists(restored) {
+		res.Outputs["cache-hit"] = "true"
+	} else {
+		restored = s.restoreKey(call.Lines("restore-keys"))
+	}
+
+	if restored == "" {
+		fmt.Fprintf(call.Stdout, "Cache not found for input keys: %s\n", key)
+		return res, nil
+	}
+
+	for i, p := range call.Lines("path") {
+		// like Post, which skips paths that don't exist, an entry may lack some
+		src := filepath.Join(restored, fmt.Sprint(i))
+		if !exists(src) {
+			continue
+		}
+
+		if err := copyTree(src, resolvePath(call.Workspace, p), nil); err != nil {
+			return nil, err
+		}
+	}
+
+	fmt.Fprintf(call.Stdout, "Cache restored from %s\n", restored)
+	return res, nil
+}
+
+// restoreKey returns the most recent entry starting with the first restore key which has any
+func (s *CacheStub) restoreKey(prefixes []string) string {
+	entries, _ := os.ReadDir(s.Dir)
+	for _, prefix := range prefixes {
+		var newest string
+		var newestMod int64
+		for _, e := range entries {
+			key, err := url.PathUnescape(e.Name())
+			if err != nil || !strings.HasPrefix(key, prefix) {
+				continue
+			}
+
+			info, err := e.Info()
+			if err == nil && info.ModTime().UnixNano() > newestMod {
+				newest, newestMod = filepath.Join(s.Dir, e.Name()), info.ModTime().UnixNano()
+			}
+		}
+
+		if newest != "" {
+			return newest
+		}
+	}
+
+	return ""
+}
+
+func (s *CacheStub) Post(ctx context.Context, call *StubCall) error {
+	// actions/cache saves with post-if: success()
+	if call.JobStatus != expressions.StatusSuccess {
+		return nil
+	}
+
+	entry := s.entry(call.With["key"])
+	if exists(entry) {
+		return nil
+	}
+
+	tmp := entry + ".tmp"
+	if err := os.RemoveAll(tmp); err != nil {
+		return err
+	}
+
+	for i, p := range call.Lines("path") {
+		src := resolvePath(call.Workspace, p)
+		if !exists(src) {
+			continue
+		}
+
+		if err := copyTree(src, filepath.Join(tmp, fmt.Sprint(i)), nil); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(call.Stdout, "Cache saved with key: %s\n", call.With["key"])
+	return os.Rename(tmp, entry)
+}
+
+// ArtifactStore stands in for actions/upload-artifact and actions/download-artifact
+// with a directory per artifact in Dir, so jobs run one after the other can pass files along
+type ArtifactStore struct {
+	Dir string
+}
+
+func (s *ArtifactStore) Upload() ActionStub {
+	return StubFunc(s.upload)
+}
+
+func (s *ArtifactStore) Download() ActionStub {
+	return StubFunc(s.download)
+}
+
+func (s *ArtifactStore) upload(ctx context.Context, call *StubCall) (*StubResult, error) {
+	name := cmp.Or(call.With["name"], "artifact")
+
+	var matches []string
+	for _, p := range call.Lines("path") {
+		found, err := filepath.Glob(resolvePath(call.Workspace, p))
+		if err != nil {
+			return nil, fmt.Errorf("path %q: %w", p, err)
+		}
+		matches = append(matches, found...)
+	}
+
+	if len(matches) == 0 {
+		switch call.With["if-no-files-found"] {
+		case "error":
+			return nil, fmt.Errorf("no files were found with the provided path: %s", call.With["path"])
+		case "ignore":
+		default:
+			fmt.Fprintf(call.Stdout, "warning: no files were found with the provided path: %s\n", call.With["path"])
+		}
+		return &StubResult{}, nil
+	}
+
+	dst := filepath.Join(s.Dir, name)
+	if err := os.RemoveAll(dst); err != nil {
+		return nil, err
+	}
+
+	// like the real action, files are stored relative to the least common ancestor of the paths
+	root := commonRoot(matches)
+	for _, m := range matches {
+		rel, err := filepath.Rel(root, m)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := copyTree(m, filepath.Join(dst, rel), nil); err != nil {
+			return nil, err
+		}
+	}
+
+	fmt.Fprintf(call.Stdout, "Artifact %s uploaded to %s\n", name, dst)
+	return &StubResult{}, nil
+}
+
+func (s *ArtifactStore) download(ctx context.Context, call *StubCall) (*StubResult, error) {
+	dst := resolvePath(call.Workspace, call.With["path"])
+
+	if name := call.With["name"]; name != "" {
+		src := filepath.Join(s.Dir, name)
+		if !exists(src) {
+			return nil, fmt.Errorf("artifact %q not found in %s", name, s.Dir)
+		}
+
+		return &StubResult{Outputs: map[string]string{"download-path": dst}}, copyTree(src, dst, nil)
+	}
+
+	// without a name every artifact is downloaded into a directory of its own
+	entries, err := os.ReadDir(s.Dir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	for _, e := range entries {
+		if err := copyTree(filepath.Join(s.Dir, e.Name()), filepath.Join(dst, e.Name()), nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return &StubResult{Outputs: map[string]string{"download-path": dst}}, nil
+}
+
+func commonRoot(paths []string) string {
+	var root []string
+	for i, p := range paths {
+		if info, err := os.Stat(p); err == nil && !info.IsDir() {
+			p = filepath.Dir(p)
+		}
+
+		parts := strings.Split(filepath.Clean(p), string(filepath.Separator))
+		if i == 0 {
+			root = parts
+			continue
+		}
+
+		n := 0
+		for n < len(root) && n < len(parts) && root[n] == parts[n] {
+			n++
+		}
+		root = root[:n]
+	}
+
+	if len(root) == 1 && root[0] == "" {
+		return string(filepath.Separator)
+	}
+
+	return strings.Join(root, string(filepath.Separator))
+}
+
+// resolvePath resolves a path input relative to the workspace, expanding a leading ~
+func resolvePath(workspace, p string) string {
+	if p == "~" || strings.HasPrefix(p, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			p = filepath.Join(home, p[1:])
+		}
+	}
+
+	if filepath.IsAbs(p) {
+		return filepath.Clean(p)
+	}
+
+	return filepath.Join(workspace, p)
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// copyTree copies the file or directory src to dst, overwriting files which exist,
+// skip leaves out paths of src and everything below them
+func copyTree(src, dst string, skip func(path string) bool) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if skip != nil && path != src && skip(path) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm()|0o700)
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			_ = os.Remove(target)
+			return os.Symlink(link, target)
+		case !d.Type().IsRegular():
+			return nil
+		}
+
+		return copyFile(path, target, info.Mode().Perm())
+	})
+}
+
+func copyFile(src, dst string, perm fs.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
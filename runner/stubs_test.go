@@ -0,0 +1,105 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cakehappens/gocto"
+	"github.com/cakehappens/gocto/expressions"
+)
+
+func TestBuiltinStubs(t *testing.T) {
+	source := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(source, ".git"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(source, ".git", "HEAD"), []byte("ref: refs/heads/main\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(source, "main.go"), []byte("package main\n"), 0o644))
+
+	state := t.TempDir()
+	artifacts := &ArtifactStore{Dir: filepath.Join(state, "artifacts")}
+
+	newRunner := func() (*Runner, *bytes.Buffer) {
+		r, out := testRunner(t)
+		r.Stub("actions/checkout", &CheckoutStub{Source: source})
+		r.Stub("actions/cache", &CacheStub{Dir: filepath.Join(state, "cache")})
+		r.Stub("actions/upload-artifact", artifacts.Upload())
+		r.Stub("actions/download-artifact", artifacts.Download())
+		return r, out
+	}
+
+	w := gocto.Workflow{
+		Name: "CI",
+		Jobs: map[string]gocto.Job{
+			"build": {
+				Outputs: map[string]string{"cache-hit": "${{ steps.cache.outputs.cache-hit }}"},
+				Steps: []gocto.Step{
+					{Uses: "actions/checkout@v4"},
+					{ID: "cache", Uses: "actions/cache@v4", With: map[string]any{"path": ".cache\n", "key": "deps-1", "restore-keys": "deps-"}},
+					{Run: `test -f main.go && test ! -e .git
+if [ -f .cache/deps ]; then echo "restored $(cat .cache/deps)"; fi
+mkdir -p .cache dist/bin && echo built > .cache/deps && echo binary > dist/bin/app`},
+					{Uses: "actions/upload-artifact@v4", With: map[string]any{"name": "app", "path": "dist/"}},
+				},
+			},
+			"deploy": {
+				Steps: []gocto.Step{
+					{Uses: "actions/download-artifact@v4", With: map[string]any{"name": "app", "path": "out"}},
+					{Run: "grep -q binary out/bin/app"},
+				},
+			},
+		},
+	}
+
+	r, _ := newRunner()
+	res, err := r.RunJob(context.Background(), w, "build")
+	require.NoError(t, err)
+	assert.Equal(t, expressions.StatusSuccess, res.Result)
+	assert.Equal(t, "false", res.Outputs["cache-hit"])
+
+	r, _ = newRunner()
+	res, err = r.RunJob(context.Background(), w, "deploy")
+	require.NoError(t, err)
+	assert.Equal(t, expressions.StatusSuccess, res.Result)
+
+	r, _ = newRunner()
+	res, err = r.RunJob(context.Background(), w, "build")
+	require.NoError(t, err)
+	assert.Equal(t, expressions.StatusSuccess, res.Result)
+	assert.Equal(t, "true", res.Outputs["cache-hit"])
+
+	w.Jobs["build"].Steps[1].With["key"] = "deps-2"
+	r, out := newRunner()
+	res, err = r.RunJob(context.Background(), w, "build")
+	require.NoError(t, err)
+	assert.Equal(t, "false", res.Outputs["cache-hit"])
+	assert.Contains(t, out.String(), "restored built\n")
+}
+
+func TestCacheStubMissingPath(t *testing.T) {
+	cache := &CacheStub{Dir: t.TempDir()}
+	call := func(workspace string) *StubCall {
+		return &StubCall{
+			With:      map[string]string{"path": "missing\n.cache", "key": "deps"},
+			Workspace: workspace,
+			Stdout:    &bytes.Buffer{},
+			JobStatus: expressions.StatusSuccess,
+		}
+	}
+
+	saved := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(saved, ".cache"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(saved, ".cache", "deps"), []byte("built"), 0o644))
+	require.NoError(t, cache.Post(context.Background(), call(saved)))
+
+	restored := t.TempDir()
+	res, err := cache.Run(context.Background(), call(restored))
+	require.NoError(t, err)
+	assert.Equal(t, "true", res.Outputs["cache-hit"])
+	assert.FileExists(t, filepath.Join(restored, ".cache", "deps"))
+	assert.NoFileExists(t, filepath.Join(restored, "missing"))
+}
@@ -0,0 +1,23 @@
+// greet is a step written with the toolkit, run by TestRunGoStep
+package main
+
+import (
+	"github.com/cakehappens/gocto/commands"
+	"github.com/cakehappens/gocto/toolkit"
+)
+
+func main() {
+	name, err := toolkit.RequireInput("name")
+	if err != nil {
+		toolkit.SetFailed(err)
+	}
+
+	toolkit.Info("hello " + name)
+	if err := toolkit.SetOutput("greeting", "hello "+name); err != nil {
+		toolkit.SetFailed(err)
+	}
+
+	if err := toolkit.AppendSummary(commands.Summary{}.Heading(1, "Greeted "+name)); err != nil {
+		toolkit.SetFailed(err)
+	}
+}
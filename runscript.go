@@ -0,0 +1,333 @@
+package gocto
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// runScript is the parsed script of a run step whose effective shell is bash or sh
+type runScript struct {
+	jobID string
+	step  int
+	shell Shell
+	file  *syntax.File
+	err   error
+}
+
+func (s runScript) path() string {
+	return stepPath(s.jobID, s.step) + ".Run"
+}
+
+// scriptParser parses the run scripts once for all the rules of a linter which look into them,
+// the parsed scripts are kept until the end of the LintAll call
+type scriptParser struct {
+	mu    sync.Mutex
+	files map[scriptKey]parsedScript
+}
+
+type scriptKey struct {
+	variant syntax.LangVariant
+	src     string
+}
+
+type parsedScript struct {
+	file *syntax.File
+	err  error
+}
+
+func (p *scriptParser) parse(variant syntax.LangVariant, src string) (*syntax.File, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := scriptKey{variant, src}
+	if parsed, ok := p.files[key]; ok {
+		return parsed.file, parsed.err
+	}
+
+	file, err := syntax.NewParser(syntax.Variant(variant)).Parse(strings.NewReader(src), "")
+	if p.files == nil {
+		p.files = make(map[scriptKey]parsedScript)
+	}
+	p.files[key] = parsedScript{file, err}
+
+	return file, err
+}
+
+func (p *scriptParser) reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.files = nil
+}
+
+// check adapts a check of the parsed scripts of a workflow to Rule.Check
+func (p *scriptParser) check(fn func(scripts []runScript) []Diagnostic) func(w Workflow) []Diagnostic {
+	return func(w Workflow) []Diagnostic {
+		return fn(p.scripts(w))
+	}
+}
+
+// scripts returns the run steps of the workflow which run in bash or sh, parsed.
+// The ${{ }} are replaced by words of the same length, so positions stay the same.
+func (p *scriptParser) scripts(w Workflow) []runScript {
+	var scripts []runScript
+	for _, jobID := range sortedJobIDs(w) {
+		job := w.Jobs[jobID]
+		for i, s := range job.Steps {
+			if s.Run == "" {
+				continue
+			}
+
+			shell, _ := w.StepShell(jobID, i)
+			variant, ok := shellVariant(shell, job.RunnerOS())
+			if !ok {
+				continue
+			}
+
+			exprs := expressionPattern.FindAllStringIndex(s.Run, -1)
+			src := []byte(s.Run)
+			for _, r := range exprs {
+				for j := r[0]; j < r[1]; j++ {
+					src[j] = 'x'
+				}
+			}
+
+			file, err := p.parse(variant, string(src))
+			scripts = append(scripts, runScript{
+				jobID: jobID,
+				step:  i,
+				shell: shell,
+				file:  file,
+				err:   err,
+			})
+		}
+	}
+
+	return scripts
+}
+
+// shellVariant returns the language of the shell for the parser, if it is bash or sh.
+// The default shell is pwsh on Windows runners.
+func shellVariant(shell Shell, runnerOS string) (syntax.LangVariant, bool) {
+	program := shellProgram(shell)
+	switch {
+	case shell == "" && runnerOS == "Windows":
+		return 0, false
+	case program == "bash":
+		return syntax.LangBash, true
+	case program == "sh":
+		return syntax.LangPOSIX, true
+	}
+
+	return 0, false
+}
+
+// shellProgram is the program running the script, e.g. bash
+func shellProgram(shell Shell) string {
+	program, _, _ := strings.Cut(shell.Template(), " ")
+	return program
+}
+
+// shellOptions returns the options the template of the shell sets, e.g. errexit for bash -e {0}
+func shellOptions(shell Shell) map[string]bool {
+	opts := make(map[string]bool)
+	fields := strings.Fields(shell.Template())
+	for i, f := range fields {
+		switch {
+		case f == "-o" && i+1 < len(fields):
+			opts[fields[i+1]] = true
+		case strings.HasPrefix(f, "-") && !strings.HasPrefix(f, "--"):
+			if strings.Contains(f, "e") {
+				opts["errexit"] = true
+			}
+			if strings.HasSuffix(f, "o") && i+1 < len(fields) {
+				opts[fields[i+1]] = true
+			}
+		}
+	}
+
+	return opts
+}
+
+// setOptions applies the options of a set builtin call, e.g. set -euo pipefail or set +e
+func setOptions(opts map[string]bool, call *syntax.CallExpr) {
+	if len(call.Args) == 0 || call.Args[0].Lit() != "set" {
+		return
+	}
+
+	args := call.Args[1:]
+	for i, a := range args {
+		arg := a.Lit()
+		if len(arg) < 2 || (arg[0] != '-' && arg[0] != '+') {
+			continue
+		}
+
+		on := arg[0] == '-'
+		if strings.Contains(arg, "e") {
+			opts["errexit"] = on
+		}
+		if strings.HasSuffix(arg, "o") && i+1 < len(args) {
+			opts[args[i+1].Lit()] = on
+		}
+	}
+}
+
+func checkRunScriptSyntax(scripts []runScript) []Diagnostic {
+	var diags []Diagnostic
+	for _, s := range scripts {
+		if s.err != nil {
+			diags = append(diags, Diagnostic{
+				Path:    s.path(),
+				Message: fmt.Sprintf("%s syntax error at %s", shellProgram(s.shell), s.err),
+			})
+		}
+	}
+
+	return diags
+}
+
+// untrustedContexts can be set by whoever triggers the workflow, e.g. by opening an issue or pushing a branch.
+// * stands for any index, e.g. github.event.commits[0].message.
+// https://docs.github.com/en/actions/reference/security/secure-use#good-practices-for-mitigating-script-injection-attacks
+var untrustedContexts = []string{
+	"github.head_ref",
+	"github.event.issue.title",
+	"github.event.issue.body",
+	"github.event.pull_request.title",
+	"github.event.pull_request.body",
+	"github.event.pull_request.head.ref",
+	"github.event.pull_request.head.label",
+	"github.event.pull_request.head.repo.default_branch",
+	"github.event.comment.body",
+	"github.event.review.body",
+	"github.event.review_comment.body",
+	"github.event.discussion.title",
+	"github.event.discussion.body",
+	"github.event.pages.*.page_name",
+	"github.event.commits.*.message",
+	"github.event.commits.*.author.email",
+	"github.event.commits.*.author.name",
+	"github.event.head_commit.message",
+	"github.event.head_commit.author.email",
+	"github.event.head_commit.author.name",
+	"github.event.workflow_run.head_branch",
+	"github.event.workflow_run.head_commit.message",
+	"github.event.workflow_run.head_commit.author.email",
+	"github.event.workflow_run.head_commit.author.name",
+}
+
+var untrustedPattern = func() *regexp.Regexp {
+	alternatives := make([]string, 0, len(untrustedContexts))
+	for _, c := range untrustedContexts {
+		alternatives = append(alternatives, strings.ReplaceAll(regexp.QuoteMeta(c), `\.\*`, `(?:\.\*|\[[^\]]*\])`))
+	}
+
+	return regexp.MustCompile(`(?i)\b(?:` + strings.Join(alternatives, "|") + `)\b`)
+}()
+
+// checkUntrustedExpression reports ${{ }} in run scripts which hold untrusted input. GitHub substitutes them
+// before the shell runs, so quotes don't help: a title like $(curl ...) or "; curl ... runs as code.
+func checkUntrustedExpression(w Workflow) []Diagnostic {
+	var diags []Diagnostic
+	for _, jobID := range sortedJobIDs(w) {
+		for i, s := range w.Jobs[jobID].Steps {
+			for _, m := range expressionPattern.FindAllStringSubmatch(s.Run, -1) {
+				context := untrustedPattern.FindString(m[1])
+				if context == "" {
+					continue
+				}
+
+				diags = append(diags, Diagnostic{
+					Path:    stepPath(jobID, i) + ".Run",
+					Message: fmt.Sprintf("%s pastes untrusted %s into the script, pass it through env and use the variable instead", m[0], context),
+				})
+			}
+		}
+	}
+
+	return diags
+}
+
+func checkMissingPipefail(scripts []runScript) []Diagnostic {
+	var diags []Diagnostic
+	for _, s := range scripts {
+		if s.err != nil {
+			continue
+		}
+
+		opts := shellOptions(s.shell)
+		var unchecked *syntax.BinaryCmd
+		syntax.Walk(s.file, func(node syntax.Node) bool {
+			switch n := node.(type) {
+			case *syntax.CallExpr:
+				setOptions(opts, n)
+			case *syntax.BinaryCmd:
+				if (n.Op == syntax.Pipe || n.Op == syntax.PipeAll) && !opts["pipefail"] && unchecked == nil {
+					unchecked = n
+				}
+			}
+
+			return true
+		})
+
+		if unchecked != nil {
+			diags = append(diags, Diagnostic{
+				Path: s.path(),
+				Message: fmt.Sprintf("the pipeline at line %d only fails when its last command does, use shell: bash or set -o pipefail",
+					unchecked.Pos().Line()),
+			})
+		}
+	}
+
+	return diags
+}
+
+// checkUncheckedCd reports cd commands whose failure doesn't stop the script,
+// which then runs in the wrong directory
+func checkUncheckedCd(scripts []runScript) []Diagnostic {
+	var diags []Diagnostic
+	for _, s := range scripts {
+		if s.err != nil {
+			continue
+		}
+
+		opts := shellOptions(s.shell)
+		checked := make(map[*syntax.Stmt]bool)
+		syntax.Walk(s.file, func(node syntax.Node) bool {
+			switch n := node.(type) {
+			case *syntax.BinaryCmd:
+				checked[n.X] = true
+				checked[n.Y] = true
+			case *syntax.IfClause:
+				for _, stmt := range n.Cond {
+					checked[stmt] = true
+				}
+			case *syntax.WhileClause:
+				for _, stmt := range n.Cond {
+					checked[stmt] = true
+				}
+			case *syntax.Stmt:
+				call, ok := n.Cmd.(*syntax.CallExpr)
+				if !ok {
+					break
+				}
+
+				setOptions(opts, call)
+				if len(call.Args) > 0 && call.Args[0].Lit() == "cd" && !opts["errexit"] && !checked[n] {
+					diags = append(diags, Diagnostic{
+						Path:    s.path(),
+						Message: fmt.Sprintf("cd at line %d runs without errexit, use cd ... || exit 1", n.Pos().Line()),
+					})
+				}
+			}
+
+			return true
+		})
+	}
+
+	return diags
+}
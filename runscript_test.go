@@ -0,0 +1,185 @@
+package gocto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"mvdan.cc/sh/v3/syntax"
+)
+
+func TestLintRunScripts(t *testing.T) {
+	type testCase struct {
+		name     string
+		runsOn   string
+		step     Step
+		expected []Diagnostic
+	}
+
+	cases := []testCase{
+		{
+			name: "syntax error",
+			step: Step{Run: "if [ -f go.mod ]; then\n  go build\n"},
+			expected: []Diagnostic{{
+				Rule:    "run-script-syntax",
+				Path:    `Jobs["build"].Steps[0].Run`,
+				Message: "bash syntax error at 1:1: `if` statement must end with `fi`",
+			}},
+		},
+		{
+			name: "untrusted expressions",
+			step: Step{Run: "echo ${{ github.head_ref }}\necho \"${{ github.event.commits[0].message }}\" \"${{ github.sha }}\"\ncat <<EOF\n${{ github.ref }}\nEOF\n"},
+			expected: []Diagnostic{
+				{
+					Rule:    "untrusted-expression",
+					Path:    `Jobs["build"].Steps[0].Run`,
+					Message: "${{ github.head_ref }} pastes untrusted github.head_ref into the script, pass it through env and use the variable instead",
+				},
+				{
+					Rule:    "untrusted-expression",
+					Path:    `Jobs["build"].Steps[0].Run`,
+					Message: "${{ github.event.commits[0].message }} pastes untrusted github.event.commits[0].message into the script, pass it through env and use the variable instead",
+				},
+			},
+		},
+		{
+			name: "untrusted expression in pwsh",
+			step: Step{Run: "echo ${{ github.head_ref }} |", Shell: ShellPwsh},
+			expected: []Diagnostic{{
+				Rule:    "untrusted-expression",
+				Path:    `Jobs["build"].Steps[0].Run`,
+				Message: "${{ github.head_ref }} pastes untrusted github.head_ref into the script, pass it through env and use the variable instead",
+			}},
+		},
+		{
+			name: "pipeline in the default shell",
+			step: Step{Run: "go test ./... | tee test.log"},
+			expected: []Diagnostic{{
+				Rule:    "missing-pipefail",
+				Path:    `Jobs["build"].Steps[0].Run`,
+				Message: "the pipeline at line 1 only fails when its last command does, use shell: bash or set -o pipefail",
+			}},
+		},
+		{
+			name: "pipeline in bash",
+			step: Step{Run: "go test ./... | tee test.log", Shell: ShellBash},
+		},
+		{
+			name: "pipeline after set -o pipefail",
+			step: Step{Run: "set -o pipefail\ngo test ./... | tee test.log"},
+		},
+		{
+			name:   "pipeline in sh on windows",
+			runsOn: "windows-latest",
+			step:   Step{Run: "go test ./... | tee test.log", Shell: ShellSh},
+			expected: []Diagnostic{{
+				Rule:    "missing-pipefail",
+				Path:    `Jobs["build"].Steps[0].Run`,
+				Message: "the pipeline at line 1 only fails when its last command does, use shell: bash or set -o pipefail",
+			}},
+		},
+		{
+			name:   "pwsh pipeline on windows",
+			runsOn: "windows-latest",
+			step:   Step{Run: "Get-ChildItem | Select-Object Name"},
+		},
+		{
+			name: "cd with errexit",
+			step: Step{Run: "cd sub\nmake"},
+		},
+		{
+			name: "cd after set +e",
+			step: Step{Run: "cd sub\nset +e\ncd other\ncd last || exit 1\nif cd maybe; then make; fi"},
+			expected: []Diagnostic{{
+				Rule:    "unchecked-cd",
+				Path:    `Jobs["build"].Steps[0].Run`,
+				Message: "cd at line 3 runs without errexit, use cd ... || exit 1",
+			}},
+		},
+		{
+			name: "cd in a custom shell",
+			step: Step{Run: "cd sub", Shell: CustomShell("bash {0}")},
+			expected: []Diagnostic{{
+				Rule:    "unchecked-cd",
+				Path:    `Jobs["build"].Steps[0].Run`,
+				Message: "cd at line 1 runs without errexit, use cd ... || exit 1",
+			}},
+		},
+	}
+
+	rules := map[string]bool{
+		"run-script-syntax":    true,
+		"untrusted-expression": true,
+		"missing-pipefail":     true,
+		"unchecked-cd":         true,
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			runsOn := tc.runsOn
+			if runsOn == "" {
+				runsOn = "ubuntu-latest"
+			}
+
+			w := Workflow{
+				Name: "scripts",
+				On:   WorkflowOn{Push: &OnPush{}},
+				Jobs: map[string]Job{
+					"build": {
+						RunsOn:         StringOrSlice{runsOn},
+						TimeoutMinutes: IntValue(10),
+						Steps:          []Step{tc.step},
+					},
+				},
+			}
+
+			var got []Diagnostic
+			for _, d := range Lint(w) {
+				if rules[d.Rule] {
+					got = append(got, Diagnostic{Rule: d.Rule, Path: d.Path, Message: d.Message})
+				}
+			}
+
+			assert.ElementsMatch(t, tc.expected, got)
+		})
+	}
+}
+
+func TestScriptParserParsesOnce(t *testing.T) {
+	p := &scriptParser{}
+	w := Workflow{
+		Name: "scripts",
+		Jobs: map[string]Job{
+			"build": {
+				RunsOn: StringOrSlice{"ubuntu-latest"},
+				Steps:  []Step{{Run: "make"}, {Uses: "actions/cache@v4"}, {Run: "cd sub"}},
+			},
+		},
+	}
+
+	first := p.scripts(w)
+	second := p.scripts(w)
+	require.Len(t, first, 2)
+	require.Len(t, second, 2)
+	for i := range first {
+		assert.Same(t, first[i].file, second[i].file)
+	}
+
+	file, err := p.parse(syntax.LangBash, "cd sub")
+	require.NoError(t, err)
+	assert.Same(t, file, first[1].file)
+
+	p.reset()
+	assert.NotSame(t, first[0].file, p.scripts(w)[0].file)
+}
+
+func TestLintAllDone(t *testing.T) {
+	var done int
+	l := NewLinter(Rule{Name: "cached", Check: func(w Workflow) []Diagnostic { return nil }, done: func() { done++ }})
+
+	l.LintAll(Workflow{Name: "a"}, Workflow{Name: "b"})
+	assert.Equal(t, 1, done)
+
+	l.Disable("cached").Lint(Workflow{Name: "a"})
+	assert.Equal(t, 1, done, "disabled rules are not done")
+}
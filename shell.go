@@ -0,0 +1,218 @@
+package gocto
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Shell runs the script of a run step, either one of the built-in shells or a custom command template
+// https://docs.github.com/en/actions/reference/workflow-syntax-for-github-actions#jobsjob_idstepsshell
+type Shell string
+
+const (
+	ShellBash Shell = "bash"
+	ShellSh   Shell = "sh"
+	// ShellPwsh is PowerShell Core, which is available on every runner
+	ShellPwsh Shell = "pwsh"
+	// ShellPowerShell is Windows PowerShell, which is only available on Windows
+	ShellPowerShell Shell = "powershell"
+	// ShellCmd is only available on Windows
+	ShellCmd    Shell = "cmd"
+	ShellPython Shell = "python"
+)
+
+var builtinShells = []Shell{ShellBash, ShellSh, ShellPwsh, ShellPowerShell, ShellCmd, ShellPython}
+
+// CustomShell is a command template, {0} is replaced with the path of the script,
+// e.g. perl {0} or bash --noprofile --norc -eo pipefail {0}
+func CustomShell(template string) Shell {
+	return Shell(template)
+}
+
+// IsCustom reports whether the shell is a command template rather than a built-in shell
+func (s Shell) IsCustom() bool {
+	return s != "" && !slices.Contains(builtinShells, s)
+}
+
+// WindowsOnly reports whether the shell is only available on Windows runners
+func (s Shell) WindowsOnly() bool {
+	return s == ShellPowerShell || s == ShellCmd
+}
+
+// Validate checks that a custom shell has the {0} placeholder for the script
+func (s Shell) Validate() error {
+	if s.IsCustom() && !strings.Contains(string(s), "{0}") {
+		return fmt.Errorf("custom shell %q has no {0} placeholder for the script", s)
+	}
+
+	return nil
+}
+
+// Template returns the command line the runner uses for the shell, {0} is the path of the script.
+// The empty shell is the default of non-Windows runners.
+func (s Shell) Template() string {
+	switch s {
+	case "":
+		return "bash -e {0}"
+	case ShellBash:
+		return "bash --noprofile --norc -eo pipefail {0}"
+	case ShellSh:
+		return "sh -e {0}"
+	case ShellPwsh, ShellPowerShell:
+		return string(s) + ` -command ". '{0}'"`
+	case ShellCmd:
+		return `%ComSpec% /D /E:ON /V:OFF /S /C "CALL "{0}""`
+	case ShellPython:
+		return "python {0}"
+	}
+
+	return string(s)
+}
+
+// Extension is the extension the script file needs for the shell to run it
+func (s Shell) Extension() string {
+	switch s {
+	case ShellPwsh, ShellPowerShell:
+		return ".ps1"
+	case ShellCmd:
+		return ".cmd"
+	case ShellPython:
+		return ".py"
+	}
+
+	return ""
+}
+
+// CommandLine splits the template of the shell into arguments, with {0} replaced by script.
+// Double quotes group words into an argument, like they do in the templates of the built-in shells.
+func (s Shell) CommandLine(script string) ([]string, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	var args []string
+	var arg strings.Builder
+	quoted, inArg := false, false
+	for _, r := range s.Template() {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			inArg = true
+		case r == ' ' && !quoted:
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+			}
+			inArg = false
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quoted {
+		return nil, fmt.Errorf("unterminated quote in shell %q", s)
+	}
+
+	if inArg {
+		args = append(args, arg.String())
+	}
+
+	for i := range args {
+		args[i] = strings.ReplaceAll(args[i], "{0}", script)
+	}
+
+	return args, nil
+}
+
+// StepShell returns the shell a run step uses, with where it is set, e.g. Jobs["build"].Defaults.Run.Shell:
+// the shell of the step, else the default of the job, else that of the workflow.
+// The empty shell means the default of the runner, bash or pwsh on Windows, and an empty path.
+func (w Workflow) StepShell(jobID string, step int) (Shell, string) {
+	job := w.Jobs[jobID]
+	switch {
+	case job.Steps[step].Shell != "":
+		return job.Steps[step].Shell, stepPath(jobID, step) + ".Shell"
+	case job.Defaults.Run.Shell != "":
+		return job.Defaults.Run.Shell, jobPath(jobID) + ".Defaults.Run.Shell"
+	case w.Defaults.Run.Shell != "":
+		return w.Defaults.Run.Shell, "Defaults.Run.Shell"
+	}
+
+	return "", ""
+}
+
+// RunnerOS is the operating system of the runners a job runs on as far as its labels tell,
+// one of Linux, Windows and macOS, like runner.os, or empty when they don't
+func (j Job) RunnerOS() string {
+	for _, label := range j.RunsOn {
+		label = strings.ToLower(label)
+		switch {
+		case strings.Contains(label, "${{"):
+			return ""
+		case label == "windows" || strings.HasPrefix(label, "windows-"):
+			return "Windows"
+		case label == "macos" || strings.HasPrefix(label, "macos-"):
+			return "macOS"
+		case label == "linux" || strings.HasPrefix(label, "ubuntu-"):
+			return "Linux"
+		}
+	}
+
+	return ""
+}
+
+func checkShellTemplate(w Workflow) []Diagnostic {
+	var diags []Diagnostic
+	check := func(path string, s Shell) {
+		if err := s.Validate(); err != nil {
+			diags = append(diags, Diagnostic{Path: path, Message: err.Error()})
+		}
+	}
+
+	check("Defaults.Run.Shell", w.Defaults.Run.Shell)
+	for _, jobID := range sortedJobIDs(w) {
+		job := w.Jobs[jobID]
+		check(jobPath(jobID)+".Defaults.Run.Shell", job.Defaults.Run.Shell)
+		for i, s := range job.Steps {
+			check(stepPath(jobID, i)+".Shell", s.Shell)
+		}
+	}
+
+	return diags
+}
+
+// checkWindowsShell reports Windows only shells of run steps, including inherited defaults, on jobs which don't run on Windows
+func checkWindowsShell(w Workflow) []Diagnostic {
+	var diags []Diagnostic
+	for _, jobID := range sortedJobIDs(w) {
+		job := w.Jobs[jobID]
+		runnerOS := job.RunnerOS()
+		if runnerOS == "" || runnerOS == "Windows" {
+			continue
+		}
+
+		reported := make(map[string]bool)
+		for i, s := range job.Steps {
+			if s.Run == "" {
+				continue
+			}
+
+			shell, path := w.StepShell(jobID, i)
+			if !shell.WindowsOnly() || reported[path] {
+				continue
+			}
+
+			reported[path] = true
+			msg := fmt.Sprintf("%s is only available on Windows, but job %q runs on %s", shell, jobID, runnerOS)
+			if path != stepPath(jobID, i)+".Shell" {
+				msg += fmt.Sprintf(", Steps[%d] inherits it", i)
+			}
+
+			diags = append(diags, Diagnostic{Path: path, Message: msg})
+		}
+	}
+
+	return diags
+}
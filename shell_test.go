@@ -0,0 +1,103 @@
+package gocto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShellCommandLine(t *testing.T) {
+	type testCase struct {
+		shell    Shell
+		expected []string
+		err      string
+	}
+
+	testCases := []testCase{
+		{shell: "", expected: []string{"bash", "-e", "/tmp/s"}},
+		{shell: ShellBash, expected: []string{"bash", "--noprofile", "--norc", "-eo", "pipefail", "/tmp/s"}},
+		{shell: ShellSh, expected: []string{"sh", "-e", "/tmp/s"}},
+		{shell: ShellPwsh, expected: []string{"pwsh", "-command", ". '/tmp/s'"}},
+		{shell: ShellPython, expected: []string{"python", "/tmp/s"}},
+		{shell: CustomShell("perl {0}"), expected: []string{"perl", "/tmp/s"}},
+		{shell: CustomShell("perl -e"), err: `custom shell "perl -e" has no {0} placeholder for the script`},
+		{shell: CustomShell(`sh -c "{0}`), err: `unterminated quote in shell "sh -c \"{0}"`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.shell), func(t *testing.T) {
+			args, err := tc.shell.CommandLine("/tmp/s")
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, args)
+		})
+	}
+}
+
+func TestStepShell(t *testing.T) {
+	w := Workflow{
+		Defaults: Defaults{Run: DefaultsRun{Shell: ShellPowerShell}},
+		Jobs: map[string]Job{
+			"linux": {
+				RunsOn:   StringOrSlice{"ubuntu-latest"},
+				Defaults: Defaults{Run: DefaultsRun{Shell: ShellCmd}},
+				Steps: []Step{
+					{Run: "dir"},
+					{Run: "dir", Shell: ShellBash},
+					{Run: "dir"},
+					{Run: "dir", Shell: CustomShell("perl")},
+				},
+			},
+			"windows": {
+				RunsOn: StringOrSlice{"windows-latest"},
+				Steps:  []Step{{Run: "dir"}},
+			},
+			"mac": {
+				RunsOn: StringOrSlice{"macos-14"},
+				Steps:  []Step{{Run: "ls"}, {Uses: "actions/checkout@v4"}},
+			},
+		},
+	}
+
+	shell, path := w.StepShell("linux", 0)
+	assert.Equal(t, ShellCmd, shell)
+	assert.Equal(t, `Jobs["linux"].Defaults.Run.Shell`, path)
+
+	shell, path = w.StepShell("linux", 1)
+	assert.Equal(t, ShellBash, shell)
+	assert.Equal(t, `Jobs["linux"].Steps[1].Shell`, path)
+
+	shell, path = w.StepShell("windows", 0)
+	assert.Equal(t, ShellPowerShell, shell)
+	assert.Equal(t, "Defaults.Run.Shell", path)
+
+	var got []Diagnostic
+	for _, d := range Lint(w) {
+		if d.Rule == "shell-template" || d.Rule == "windows-shell" {
+			got = append(got, Diagnostic{Rule: d.Rule, Path: d.Path, Message: d.Message})
+		}
+	}
+
+	assert.ElementsMatch(t, []Diagnostic{
+		{
+			Rule:    "shell-template",
+			Path:    `Jobs["linux"].Steps[3].Shell`,
+			Message: `custom shell "perl" has no {0} placeholder for the script`,
+		},
+		{
+			Rule:    "windows-shell",
+			Path:    `Jobs["linux"].Defaults.Run.Shell`,
+			Message: `cmd is only available on Windows, but job "linux" runs on Linux, Steps[0] inherits it`,
+		},
+		{
+			Rule:    "windows-shell",
+			Path:    "Defaults.Run.Shell",
+			Message: `powershell is only available on Windows, but job "mac" runs on macOS, Steps[0] inherits it`,
+		},
+	}, got)
+}
@@ -3,6 +3,10 @@ package gocto
 import (
 	"fmt"
 	"strings"
+
+	"github.com/cakehappens/gocto/expressions"
+	"github.com/cakehappens/gocto/internal/util"
+	"github.com/cakehappens/gocto/toolkit"
 )
 
 // TernaryExpressionStep is a better way to do if/else than with pure expressions,
@@ -30,3 +34,23 @@ fi
 		Shell: ShellBash,
 	}
 }
+
+// GoRunStep runs the Go program pkg with go run, e.g. ./cmd/release.
+// The inputs are passed as INPUT_* environment variables, which the program reads with toolkit.GetInput.
+// The step needs the repository checked out and Go set up.
+func GoRunStep(pkg string, inputs map[string]expressions.Expression) Step {
+	var env map[string]string
+	for name, value := range inputs {
+		if env == nil {
+			env = make(map[string]string, len(inputs))
+		}
+		// string literals, e.g. expressions.FromTemplate("dev"), render as plain strings
+		env[toolkit.InputVar(name)] = value.Template()
+	}
+
+	return Step{
+		Run:   "go run " + util.BashQuote(pkg),
+		Shell: ShellBash,
+		Env:   env,
+	}
+}
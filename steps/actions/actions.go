@@ -0,0 +1,95 @@
+// Package actions provides typed builders for commonly used actions.
+//
+// Each builder is a struct of the action's inputs. Zero values are omitted from
+// the rendered step so the action's own default applies; where the zero value
+// is itself meaningful (for example a fetch depth of 0) the field is a pointer.
+package actions
+
+import (
+	"strings"
+
+	"github.com/cakehappens/gocto"
+)
+
+// Pinned references for every action in this package. Bumping a version here
+// bumps it for every workflow generated from these builders.
+const (
+	CheckoutUses                = "actions/checkout@v4.2.2"
+	SetupGoUses                 = "actions/setup-go@v5.5.0"
+	SetupNodeUses               = "actions/setup-node@v4.4.0"
+	SetupPythonUses             = "actions/setup-python@v5.6.0"
+	CacheUses                   = "actions/cache@v4.2.3"
+	UploadArtifactUses          = "actions/upload-artifact@v4.6.2"
+	DownloadArtifactUses        = "actions/download-artifact@v4.3.0"
+	GithubScriptUses            = "actions/github-script@v7.0.1"
+	ConfigureAWSCredentialsUses = "aws-actions/configure-aws-credentials@v4.2.1"
+	DockerBuildPushUses         = "docker/build-push-action@v6.18.0"
+)
+
+// Bool returns a pointer to val, for inputs whose default is true.
+func Bool(val bool) *bool {
+	return gocto.Ptr(val)
+}
+
+// Int returns a pointer to val, for inputs where 0 is meaningful.
+func Int(val int) *int {
+	return gocto.Ptr(val)
+}
+
+type inputs map[string]any
+
+func (in inputs) str(key, val string) {
+	if val != "" {
+		in[key] = val
+	}
+}
+
+func (in inputs) boolean(key string, val bool) {
+	if val {
+		in[key] = true
+	}
+}
+
+func (in inputs) boolPtr(key string, val *bool) {
+	if val != nil {
+		in[key] = *val
+	}
+}
+
+func (in inputs) integer(key string, val int) {
+	if val != 0 {
+		in[key] = val
+	}
+}
+
+func (in inputs) intPtr(key string, val *int) {
+	if val != nil {
+		in[key] = *val
+	}
+}
+
+// lines renders a list input the way most actions expect: newline separated
+func (in inputs) lines(key string, vals []string) {
+	if len(vals) > 0 {
+		in[key] = strings.Join(vals, "\n")
+	}
+}
+
+// csv renders a list input for actions which expect comma separated values
+func (in inputs) csv(key string, vals []string) {
+	if len(vals) > 0 {
+		in[key] = strings.Join(vals, ",")
+	}
+}
+
+func (in inputs) step(uses string) gocto.Step {
+	s := gocto.Step{
+		Uses: uses,
+	}
+
+	if len(in) > 0 {
+		s.With = in
+	}
+
+	return s
+}
@@ -0,0 +1,65 @@
+package actions
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/cakehappens/gocto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStepBuilders(t *testing.T) {
+	type testCase struct {
+		name     string
+		step     gocto.Step
+		expected string
+	}
+
+	cases := []testCase{
+		{
+			name:     "checkout defaults",
+			step:     Checkout{}.Step(),
+			expected: `{"uses":"actions/checkout@v4.2.2"}`,
+		},
+		{
+			name: "checkout full history",
+			step: Checkout{
+				FetchDepth:         Int(0),
+				PersistCredentials: Bool(false),
+			}.Step(),
+			expected: `{"uses":"actions/checkout@v4.2.2","with":{"fetch-depth":0,"persist-credentials":false}}`,
+		},
+		{
+			name: "cache multi-line paths",
+			step: Cache{
+				Path:        []string{"~/.cache/go-build", "~/go/pkg/mod"},
+				Key:         "go-${{ hashFiles('**/go.sum') }}",
+				RestoreKeys: []string{"go-"},
+			}.Step(),
+			expected: `{"uses":"actions/cache@v4.2.3","with":{"key":"go-${{ hashFiles('**/go.sum') }}","path":"~/.cache/go-build\n~/go/pkg/mod","restore-keys":"go-"}}`,
+		},
+		{
+			name: "build push platforms",
+			step: DockerBuildPush{
+				Platforms: []string{"linux/amd64", "linux/arm64"},
+				Push:      true,
+			}.Step(),
+			expected: `{"uses":"docker/build-push-action@v6.18.0","with":{"platforms":"linux/amd64,linux/arm64","push":true}}`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			stepJson, err := json.Marshal(tc.step)
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.expected, string(stepJson))
+		})
+	}
+}
+
+func TestOutputs(t *testing.T) {
+	assert.Equal(t, "${{steps.co.outputs.commit}}", CheckoutOutputs("co").Commit().String())
+	assert.Equal(t, "${{steps.go.outputs.go-version}}", SetupGoOutputs("go").GoVersion().String())
+	assert.Equal(t, "${{steps.build.outputs.digest}}", DockerBuildPushOutputs("build").Digest().String())
+}
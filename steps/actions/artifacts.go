@@ -0,0 +1,104 @@
+package actions
+
+import (
+	"github.com/cakehappens/gocto"
+	"github.com/cakehappens/gocto/expressions"
+)
+
+type IfNoFilesFound string
+
+const (
+	IfNoFilesFoundWarn   IfNoFilesFound = "warn"
+	IfNoFilesFoundError  IfNoFilesFound = "error"
+	IfNoFilesFoundIgnore IfNoFilesFound = "ignore"
+)
+
+// UploadArtifact
+// https://github.com/actions/upload-artifact
+type UploadArtifact struct {
+	// Name defaults to "artifact"
+	Name string
+	// Path is the list of files, directories and globs to upload, required
+	Path []string
+	// IfNoFilesFound defaults to IfNoFilesFoundWarn
+	IfNoFilesFound IfNoFilesFound
+	// RetentionDays defaults to the repository's retention setting
+	RetentionDays int
+	// CompressionLevel is 0-9 and defaults to 6, use Int(0) to disable compression
+	CompressionLevel *int
+	// Overwrite defaults to false
+	Overwrite bool
+	// IncludeHiddenFiles defaults to false
+	IncludeHiddenFiles bool
+}
+
+func (u UploadArtifact) Step() gocto.Step {
+	in := inputs{}
+	in.str("name", u.Name)
+	in.lines("path", u.Path)
+	in.str("if-no-files-found", string(u.IfNoFilesFound))
+	in.integer("retention-days", u.RetentionDays)
+	in.intPtr("compression-level", u.CompressionLevel)
+	in.boolean("overwrite", u.Overwrite)
+	in.boolean("include-hidden-files", u.IncludeHiddenFiles)
+
+	return in.step(UploadArtifactUses)
+}
+
+// UploadArtifactOutputs accesses the outputs of an upload-artifact step by its step ID
+type UploadArtifactOutputs string
+
+// ArtifactID is the ID of the uploaded artifact
+func (o UploadArtifactOutputs) ArtifactID() expressions.Expression {
+	return expressions.StepOutput(string(o), "artifact-id")
+}
+
+// ArtifactURL is the URL to download the uploaded artifact
+func (o UploadArtifactOutputs) ArtifactURL() expressions.Expression {
+	return expressions.StepOutput(string(o), "artifact-url")
+}
+
+// ArtifactDigest is the SHA-256 digest of the uploaded artifact
+func (o UploadArtifactOutputs) ArtifactDigest() expressions.Expression {
+	return expressions.StepOutput(string(o), "artifact-digest")
+}
+
+// DownloadArtifact
+// https://github.com/actions/download-artifact
+type DownloadArtifact struct {
+	// Name downloads a single artifact, all artifacts are downloaded when Name and Pattern are unset
+	Name string
+	// Pattern is a glob matched against artifact names
+	Pattern string
+	// Path defaults to github.workspace
+	Path string
+	// MergeMultiple extracts every matched artifact into Path, defaults to false
+	MergeMultiple bool
+	// GithubToken is required when Repository or RunID are set
+	GithubToken string
+	// Repository defaults to github.repository
+	Repository string
+	// RunID defaults to github.run_id
+	RunID string
+}
+
+func (d DownloadArtifact) Step() gocto.Step {
+	in := inputs{}
+	in.str("name", d.Name)
+	in.str("pattern", d.Pattern)
+	in.str("path", d.Path)
+	in.boolean("merge-multiple", d.MergeMultiple)
+	in.str("github-token", d.GithubToken)
+	in.str("repository", d.Repository)
+	in.str("run-id", d.RunID)
+
+	return in.step(DownloadArtifactUses)
+}
+
+// DownloadArtifactOutputs accesses the outputs of a download-artifact step by its step ID
+type DownloadArtifactOutputs string
+
+// DownloadPath is the absolute path the artifacts were downloaded to
+func (o DownloadArtifactOutputs) DownloadPath() expressions.Expression {
+	return expressions.StepOutput(string(o), "download-path")
+}
@@ -0,0 +1,93 @@
+package actions
+
+import (
+	"github.com/cakehappens/gocto"
+	"github.com/cakehappens/gocto/expressions"
+)
+
+// ConfigureAWSCredentials
+// https://github.com/aws-actions/configure-aws-credentials
+type ConfigureAWSCredentials struct {
+	// AWSRegion is required
+	AWSRegion string
+	// RoleToAssume is an ARN, when set with no access keys OIDC is used and the job needs id-token: write
+	RoleToAssume       string
+	AWSAccessKeyID     string
+	AWSSecretAccessKey string
+	AWSSessionToken    string
+	// WebIdentityTokenFile is used instead of OIDC when set
+	WebIdentityTokenFile string
+	// RoleChaining uses existing credentials to assume RoleToAssume, defaults to false
+	RoleChaining bool
+	// Audience defaults to "sts.amazonaws.com"
+	Audience  string
+	HTTPProxy string
+	// MaskAWSAccountID defaults to false
+	MaskAWSAccountID bool
+	// RoleDurationSeconds defaults to 3600
+	RoleDurationSeconds int
+	RoleExternalID      string
+	// RoleSessionName defaults to "GitHubActions"
+	RoleSessionName string
+	// RoleSkipSessionTagging defaults to false
+	RoleSkipSessionTagging bool
+	InlineSessionPolicy    string
+	ManagedSessionPolicies []string
+	// OutputCredentials sets the credential step outputs, defaults to false
+	OutputCredentials bool
+	// UnsetCurrentCredentials defaults to false
+	UnsetCurrentCredentials bool
+	// DisableRetry defaults to false
+	DisableRetry bool
+	// RetryMaxAttempts defaults to 12
+	RetryMaxAttempts int
+}
+
+func (c ConfigureAWSCredentials) Step() gocto.Step {
+	in := inputs{}
+	in.str("aws-region", c.AWSRegion)
+	in.str("role-to-assume", c.RoleToAssume)
+	in.str("aws-access-key-id", c.AWSAccessKeyID)
+	in.str("aws-secret-access-key", c.AWSSecretAccessKey)
+	in.str("aws-session-token", c.AWSSessionToken)
+	in.str("web-identity-token-file", c.WebIdentityTokenFile)
+	in.boolean("role-chaining", c.RoleChaining)
+	in.str("audience", c.Audience)
+	in.str("http-proxy", c.HTTPProxy)
+	in.boolean("mask-aws-account-id", c.MaskAWSAccountID)
+	in.integer("role-duration-seconds", c.RoleDurationSeconds)
+	in.str("role-external-id", c.RoleExternalID)
+	in.str("role-session-name", c.RoleSessionName)
+	in.boolean("role-skip-session-tagging", c.RoleSkipSessionTagging)
+	in.str("inline-session-policy", c.InlineSessionPolicy)
+	in.lines("managed-session-policies", c.ManagedSessionPolicies)
+	in.boolean("output-credentials", c.OutputCredentials)
+	in.boolean("unset-current-credentials", c.UnsetCurrentCredentials)
+	in.boolean("disable-retry", c.DisableRetry)
+	in.integer("retry-max-attempts", c.RetryMaxAttempts)
+
+	return in.step(ConfigureAWSCredentialsUses)
+}
+
+// ConfigureAWSCredentialsOutputs accesses the outputs of a configure-aws-credentials step by its step ID
+type ConfigureAWSCredentialsOutputs string
+
+// AWSAccountID is the account the credentials belong to
+func (o ConfigureAWSCredentialsOutputs) AWSAccountID() expressions.Expression {
+	return expressions.StepOutput(string(o), "aws-account-id")
+}
+
+// AWSAccessKeyID is only set when OutputCredentials is true
+func (o ConfigureAWSCredentialsOutputs) AWSAccessKeyID() expressions.Expression {
+	return expressions.StepOutput(string(o), "aws-access-key-id")
+}
+
+// AWSSecretAccessKey is only set when OutputCredentials is true
+func (o ConfigureAWSCredentialsOutputs) AWSSecretAccessKey() expressions.Expression {
+	return expressions.StepOutput(string(o), "aws-secret-access-key")
+}
+
+// AWSSessionToken is only set when OutputCredentials is true
+func (o ConfigureAWSCredentialsOutputs) AWSSessionToken() expressions.Expression {
+	return expressions.StepOutput(string(o), "aws-session-token")
+}
@@ -0,0 +1,46 @@
+package actions
+
+import (
+	"github.com/cakehappens/gocto"
+	"github.com/cakehappens/gocto/expressions"
+)
+
+// Cache
+// https://github.com/actions/cache
+type Cache struct {
+	// Path is the list of files, directories and globs to cache, required
+	Path []string
+	// Key is the explicit key for saving and restoring the cache, required
+	Key string
+	// RestoreKeys are ordered prefixes tried when Key has no exact match
+	RestoreKeys []string
+	// UploadChunkSize is in bytes, defaults to the cache service's chunk size
+	UploadChunkSize int
+	// EnableCrossOsArchive defaults to false
+	EnableCrossOsArchive bool
+	// FailOnCacheMiss defaults to false
+	FailOnCacheMiss bool
+	// LookupOnly checks for a cache entry without downloading it, defaults to false
+	LookupOnly bool
+}
+
+func (c Cache) Step() gocto.Step {
+	in := inputs{}
+	in.lines("path", c.Path)
+	in.str("key", c.Key)
+	in.lines("restore-keys", c.RestoreKeys)
+	in.integer("upload-chunk-size", c.UploadChunkSize)
+	in.boolean("enableCrossOsArchive", c.EnableCrossOsArchive)
+	in.boolean("fail-on-cache-miss", c.FailOnCacheMiss)
+	in.boolean("lookup-only", c.LookupOnly)
+
+	return in.step(CacheUses)
+}
+
+// CacheOutputs accesses the outputs of a cache step by its step ID
+type CacheOutputs string
+
+// CacheHit is "true" only when Key matched exactly
+func (o CacheOutputs) CacheHit() expressions.Expression {
+	return expressions.StepOutput(string(o), "cache-hit")
+}
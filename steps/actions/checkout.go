@@ -0,0 +1,86 @@
+package actions
+
+import (
+	"github.com/cakehappens/gocto"
+	"github.com/cakehappens/gocto/expressions"
+)
+
+// Checkout
+// https://github.com/actions/checkout
+type Checkout struct {
+	// Repository name with owner, defaults to github.repository
+	Repository string
+	// Ref to check out, defaults to the ref or SHA for the triggering event
+	Ref string
+	// Token used to fetch the repository, defaults to github.token
+	Token         string
+	SSHKey        string
+	SSHKnownHosts string
+	// SSHStrict defaults to true
+	SSHStrict *bool
+	// SSHUser defaults to "git"
+	SSHUser string
+	// PersistCredentials defaults to true
+	PersistCredentials *bool
+	// Path relative to github.workspace, defaults to the workspace itself
+	Path string
+	// Clean runs git clean -ffdx && git reset --hard HEAD before fetching, defaults to true
+	Clean *bool
+	// Filter is a partial clone filter, overrides SparseCheckout when set
+	Filter         string
+	SparseCheckout []string
+	// SparseCheckoutConeMode defaults to true
+	SparseCheckoutConeMode *bool
+	// FetchDepth defaults to 1, use Int(0) to fetch all history
+	FetchDepth *int
+	// FetchTags defaults to false
+	FetchTags bool
+	// ShowProgress defaults to true
+	ShowProgress *bool
+	// LFS defaults to false
+	LFS bool
+	// Submodules is "true" or "recursive", defaults to not checking out submodules
+	Submodules string
+	// SetSafeDirectory defaults to true
+	SetSafeDirectory *bool
+	GithubServerURL  string
+}
+
+func (c Checkout) Step() gocto.Step {
+	in := inputs{}
+	in.str("repository", c.Repository)
+	in.str("ref", c.Ref)
+	in.str("token", c.Token)
+	in.str("ssh-key", c.SSHKey)
+	in.str("ssh-known-hosts", c.SSHKnownHosts)
+	in.boolPtr("ssh-strict", c.SSHStrict)
+	in.str("ssh-user", c.SSHUser)
+	in.boolPtr("persist-credentials", c.PersistCredentials)
+	in.str("path", c.Path)
+	in.boolPtr("clean", c.Clean)
+	in.str("filter", c.Filter)
+	in.lines("sparse-checkout", c.SparseCheckout)
+	in.boolPtr("sparse-checkout-cone-mode", c.SparseCheckoutConeMode)
+	in.intPtr("fetch-depth", c.FetchDepth)
+	in.boolean("fetch-tags", c.FetchTags)
+	in.boolPtr("show-progress", c.ShowProgress)
+	in.boolean("lfs", c.LFS)
+	in.str("submodules", c.Submodules)
+	in.boolPtr("set-safe-directory", c.SetSafeDirectory)
+	in.str("github-server-url", c.GithubServerURL)
+
+	return in.step(CheckoutUses)
+}
+
+// CheckoutOutputs accesses the outputs of a checkout step by its step ID
+type CheckoutOutputs string
+
+// Ref is the branch, tag or SHA that was checked out
+func (o CheckoutOutputs) Ref() expressions.Expression {
+	return expressions.StepOutput(string(o), "ref")
+}
+
+// Commit is the commit SHA that was checked out
+func (o CheckoutOutputs) Commit() expressions.Expression {
+	return expressions.StepOutput(string(o), "commit")
+}
@@ -0,0 +1,83 @@
+package actions
+
+import (
+	"github.com/cakehappens/gocto"
+	"github.com/cakehappens/gocto/expressions"
+)
+
+// DockerBuildPush
+// https://github.com/docker/build-push-action
+type DockerBuildPush struct {
+	// Context defaults to the Git context of the triggering ref
+	Context string
+	// File defaults to {Context}/Dockerfile
+	File        string
+	Target      string
+	BuildArgs   []string
+	Labels      []string
+	Annotations []string
+	Tags        []string
+	Platforms   []string
+	CacheFrom   []string
+	CacheTo     []string
+	Outputs     []string
+	Secrets     []string
+	Builder     string
+	// Load is shorthand for --output=type=docker, defaults to false
+	Load bool
+	// Push is shorthand for --output=type=registry, defaults to false
+	Push bool
+	// Pull always attempts to pull referenced images, defaults to false
+	Pull bool
+	// NoCache defaults to false
+	NoCache bool
+	// Provenance is a provenance attestation spec, e.g. "mode=max" or "false"
+	Provenance string
+	// SBOM is an SBOM attestation spec, e.g. "true"
+	SBOM        string
+	GithubToken string
+}
+
+func (d DockerBuildPush) Step() gocto.Step {
+	in := inputs{}
+	in.str("context", d.Context)
+	in.str("file", d.File)
+	in.str("target", d.Target)
+	in.lines("build-args", d.BuildArgs)
+	in.lines("labels", d.Labels)
+	in.lines("annotations", d.Annotations)
+	in.lines("tags", d.Tags)
+	in.csv("platforms", d.Platforms)
+	in.lines("cache-from", d.CacheFrom)
+	in.lines("cache-to", d.CacheTo)
+	in.lines("outputs", d.Outputs)
+	in.lines("secrets", d.Secrets)
+	in.str("builder", d.Builder)
+	in.boolean("load", d.Load)
+	in.boolean("push", d.Push)
+	in.boolean("pull", d.Pull)
+	in.boolean("no-cache", d.NoCache)
+	in.str("provenance", d.Provenance)
+	in.str("sbom", d.SBOM)
+	in.str("github-token", d.GithubToken)
+
+	return in.step(DockerBuildPushUses)
+}
+
+// DockerBuildPushOutputs accesses the outputs of a build-push-action step by its step ID
+type DockerBuildPushOutputs string
+
+// ImageID is the ID of the built image
+func (o DockerBuildPushOutputs) ImageID() expressions.Expression {
+	return expressions.StepOutput(string(o), "imageid")
+}
+
+// Digest is the digest of the pushed image
+func (o DockerBuildPushOutputs) Digest() expressions.Expression {
+	return expressions.StepOutput(string(o), "digest")
+}
+
+// Metadata is the JSON build result metadata
+func (o DockerBuildPushOutputs) Metadata() expressions.Expression {
+	return expressions.StepOutput(string(o), "metadata")
+}
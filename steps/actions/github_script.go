@@ -0,0 +1,54 @@
+package actions
+
+import (
+	"github.com/cakehappens/gocto"
+	"github.com/cakehappens/gocto/expressions"
+)
+
+type ResultEncoding string
+
+const (
+	ResultEncodingJSON   ResultEncoding = "json"
+	ResultEncodingString ResultEncoding = "string"
+)
+
+// GithubScript
+// https://github.com/actions/github-script
+type GithubScript struct {
+	// Script is the body of an async function, required
+	Script string
+	// GithubToken defaults to github.token
+	GithubToken string
+	// Debug defaults to false
+	Debug     bool
+	UserAgent string
+	Previews  []string
+	// ResultEncoding defaults to ResultEncodingJSON
+	ResultEncoding ResultEncoding
+	// Retries defaults to 0
+	Retries int
+	// RetryExemptStatusCodes defaults to 400,401,403,404,422
+	RetryExemptStatusCodes []string
+}
+
+func (g GithubScript) Step() gocto.Step {
+	in := inputs{}
+	in.str("script", g.Script)
+	in.str("github-token", g.GithubToken)
+	in.boolean("debug", g.Debug)
+	in.str("user-agent", g.UserAgent)
+	in.csv("previews", g.Previews)
+	in.str("result-encoding", string(g.ResultEncoding))
+	in.integer("retries", g.Retries)
+	in.csv("retry-exempt-status-codes", g.RetryExemptStatusCodes)
+
+	return in.step(GithubScriptUses)
+}
+
+// GithubScriptOutputs accesses the outputs of a github-script step by its step ID
+type GithubScriptOutputs string
+
+// Result is the value returned from Script, encoded per ResultEncoding
+func (o GithubScriptOutputs) Result() expressions.Expression {
+	return expressions.StepOutput(string(o), "result")
+}
@@ -0,0 +1,169 @@
+package actions
+
+import (
+	"github.com/cakehappens/gocto"
+	"github.com/cakehappens/gocto/expressions"
+)
+
+// SetupGo
+// https://github.com/actions/setup-go
+type SetupGo struct {
+	// GoVersion is a version or semver range, e.g. "1.25" or ">=1.24.0"
+	GoVersion string
+	// GoVersionFile is a path to go.mod, go.work, .go-version or .tool-versions
+	GoVersionFile string
+	// CheckLatest defaults to false
+	CheckLatest bool
+	Token       string
+	// Cache defaults to true
+	Cache *bool
+	// CacheDependencyPath defaults to go.sum in the repository root
+	CacheDependencyPath []string
+	Architecture        string
+}
+
+func (s SetupGo) Step() gocto.Step {
+	in := inputs{}
+	in.str("go-version", s.GoVersion)
+	in.str("go-version-file", s.GoVersionFile)
+	in.boolean("check-latest", s.CheckLatest)
+	in.str("token", s.Token)
+	in.boolPtr("cache", s.Cache)
+	in.lines("cache-dependency-path", s.CacheDependencyPath)
+	in.str("architecture", s.Architecture)
+
+	return in.step(SetupGoUses)
+}
+
+// SetupGoOutputs accesses the outputs of a setup-go step by its step ID
+type SetupGoOutputs string
+
+// GoVersion is the installed Go version
+func (o SetupGoOutputs) GoVersion() expressions.Expression {
+	return expressions.StepOutput(string(o), "go-version")
+}
+
+// CacheHit reports whether the module cache was restored
+func (o SetupGoOutputs) CacheHit() expressions.Expression {
+	return expressions.StepOutput(string(o), "cache-hit")
+}
+
+type NodePackageManager string
+
+const (
+	NodePackageManagerNPM  NodePackageManager = "npm"
+	NodePackageManagerYarn NodePackageManager = "yarn"
+	NodePackageManagerPNPM NodePackageManager = "pnpm"
+)
+
+// SetupNode
+// https://github.com/actions/setup-node
+type SetupNode struct {
+	// NodeVersion is a version, alias or range, e.g. "22", "lts/*" or ">=20"
+	NodeVersion string
+	// NodeVersionFile is a path to .nvmrc, .node-version, .tool-versions or package.json
+	NodeVersionFile string
+	Architecture    string
+	// CheckLatest defaults to false
+	CheckLatest bool
+	// RegistryURL sets up an .npmrc to authenticate against this registry
+	RegistryURL string
+	Scope       string
+	Token       string
+	// Cache is unset by default, which disables caching
+	Cache               NodePackageManager
+	CacheDependencyPath []string
+}
+
+func (s SetupNode) Step() gocto.Step {
+	in := inputs{}
+	in.str("node-version", s.NodeVersion)
+	in.str("node-version-file", s.NodeVersionFile)
+	in.str("architecture", s.Architecture)
+	in.boolean("check-latest", s.CheckLatest)
+	in.str("registry-url", s.RegistryURL)
+	in.str("scope", s.Scope)
+	in.str("token", s.Token)
+	in.str("cache", string(s.Cache))
+	in.lines("cache-dependency-path", s.CacheDependencyPath)
+
+	return in.step(SetupNodeUses)
+}
+
+// SetupNodeOutputs accesses the outputs of a setup-node step by its step ID
+type SetupNodeOutputs string
+
+// NodeVersion is the installed Node.js version
+func (o SetupNodeOutputs) NodeVersion() expressions.Expression {
+	return expressions.StepOutput(string(o), "node-version")
+}
+
+// CacheHit reports whether the package manager cache was restored
+func (o SetupNodeOutputs) CacheHit() expressions.Expression {
+	return expressions.StepOutput(string(o), "cache-hit")
+}
+
+type PythonPackageManager string
+
+const (
+	PythonPackageManagerPip    PythonPackageManager = "pip"
+	PythonPackageManagerPipenv PythonPackageManager = "pipenv"
+	PythonPackageManagerPoetry PythonPackageManager = "poetry"
+)
+
+// SetupPython
+// https://github.com/actions/setup-python
+type SetupPython struct {
+	// PythonVersion is a version or range, e.g. "3.13" or "pypy3.10"
+	PythonVersion string
+	// PythonVersionFile is a path to .python-version, pyproject.toml or .tool-versions
+	PythonVersionFile string
+	// Cache is unset by default, which disables caching
+	Cache        PythonPackageManager
+	Architecture string
+	// CheckLatest defaults to false
+	CheckLatest bool
+	Token       string
+	// CacheDependencyPath defaults to the package manager's lock file in the repository root
+	CacheDependencyPath []string
+	// UpdateEnvironment exports pythonLocation and friends, defaults to true
+	UpdateEnvironment *bool
+	// AllowPrereleases defaults to false
+	AllowPrereleases bool
+	// Freethreaded defaults to false
+	Freethreaded bool
+}
+
+func (s SetupPython) Step() gocto.Step {
+	in := inputs{}
+	in.str("python-version", s.PythonVersion)
+	in.str("python-version-file", s.PythonVersionFile)
+	in.str("cache", string(s.Cache))
+	in.str("architecture", s.Architecture)
+	in.boolean("check-latest", s.CheckLatest)
+	in.str("token", s.Token)
+	in.lines("cache-dependency-path", s.CacheDependencyPath)
+	in.boolPtr("update-environment", s.UpdateEnvironment)
+	in.boolean("allow-prereleases", s.AllowPrereleases)
+	in.boolean("freethreaded", s.Freethreaded)
+
+	return in.step(SetupPythonUses)
+}
+
+// SetupPythonOutputs accesses the outputs of a setup-python step by its step ID
+type SetupPythonOutputs string
+
+// PythonVersion is the installed Python version
+func (o SetupPythonOutputs) PythonVersion() expressions.Expression {
+	return expressions.StepOutput(string(o), "python-version")
+}
+
+// PythonPath is the absolute path to the Python executable
+func (o SetupPythonOutputs) PythonPath() expressions.Expression {
+	return expressions.StepOutput(string(o), "python-path")
+}
+
+// CacheHit reports whether the package manager cache was restored
+func (o SetupPythonOutputs) CacheHit() expressions.Expression {
+	return expressions.StepOutput(string(o), "cache-hit")
+}
@@ -0,0 +1,350 @@
+// Package script builds the scripts of run steps without quoting bugs.
+//
+// Literal words are quoted for the shell, and expressions are never pasted into the script:
+// each one is passed through an environment variable of the step, so its value can't be run as code.
+// Scripts fail on the first failing command, for bash with set -euo pipefail.
+//
+//	step := script.Bash().
+//		Group("Build", script.Bash().Run("go", "build", "-ldflags", script.Concat("-X main.version=", version), "./...")).
+//		Retry(3, 10*time.Second, "docker", "push", image).
+//		Step()
+package script
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cakehappens/gocto"
+	"github.com/cakehappens/gocto/commands"
+	"github.com/cakehappens/gocto/expressions"
+	"github.com/cakehappens/gocto/internal/util"
+)
+
+// Shell is the shell a Script is written for, BashShell or PwshShell.
+// Being part of the type, a script can't mix the syntax of both.
+type Shell interface {
+	shell() gocto.Shell
+}
+
+type BashShell struct{}
+
+func (BashShell) shell() gocto.Shell { return gocto.ShellBash }
+
+type PwshShell struct{}
+
+func (PwshShell) shell() gocto.Shell { return gocto.ShellPwsh }
+
+// Script is a bash or pwsh script. Like the builders of gocto, its methods return a modified copy.
+//
+// The words of commands can be a string literal, an expressions.Expression, an EnvVar or a Concat of them,
+// other values are formatted with fmt.Sprint.
+type Script[S Shell] struct {
+	lines []string
+	// env holds the variables passing expressions, keyed by name
+	env map[string]expressions.Expression
+}
+
+// EnvVar refers to an environment variable, e.g. EnvVar("GITHUB_SHA")
+type EnvVar string
+
+// Concat joins words into a single one, e.g. Concat("--tag=app:", version)
+func Concat(parts ...any) any {
+	return concat(parts)
+}
+
+type concat []any
+
+func Bash() Script[BashShell] {
+	return Script[BashShell]{}
+}
+
+func Pwsh() Script[PwshShell] {
+	return Script[PwshShell]{}
+}
+
+func (s Script[S]) shell() gocto.Shell {
+	var shell S
+	return shell.shell()
+}
+
+func (s Script[S]) add(lines ...string) Script[S] {
+	s.lines = append(slices.Clone(s.lines), lines...)
+	return s
+}
+
+// Run adds a command, the first word is the program
+func (s Script[S]) Run(command ...any) Script[S] {
+	s, line := s.command(command)
+	return s.add(line)
+}
+
+// Line adds a line as is, for what the builder doesn't cover
+func (s Script[S]) Line(raw string) Script[S] {
+	return s.add(raw)
+}
+
+// Heredoc writes content to the file at path, without expanding anything in it
+func (s Script[S]) Heredoc(path, content string) Script[S] {
+	content = strings.TrimSuffix(escapeExpressions(content), "\n")
+	s, target := s.word(path)
+
+	if s.shell() == gocto.ShellPwsh {
+		// a line starting with '@ would end the here-string, such content goes in a quoted string instead
+		if hereStringEnd.MatchString(content) {
+			return s.add("Set-Content -Path " + target + " -Value '" + strings.ReplaceAll(content, "'", "''") + "'")
+		}
+
+		return s.add("@'", content, "'@ | Set-Content -Path "+target)
+	}
+
+	delimiter := "EOF"
+	for slices.Contains(strings.Split(content, "\n"), delimiter) {
+		delimiter += "_"
+	}
+
+	return s.add("cat > "+target+" <<'"+delimiter+"'", content, delimiter)
+}
+
+var hereStringEnd = regexp.MustCompile(`(?m)^\s*'@`)
+
+// Group folds the output of body in the log under title
+func (s Script[S]) Group(title string, body Script[S]) Script[S] {
+	// the body may have derived the name of a variable of s for another expression, e.g. A_B for a-b and a.b
+	var renames []string
+	for _, name := range slices.Sorted(maps.Keys(body.env)) {
+		var renamed EnvVar
+		s, renamed = s.exprVar(body.env[name])
+		if string(renamed) != name {
+			renames = append(renames, exprRef(EnvVar(name)), exprRef(renamed))
+		}
+	}
+
+	lines := body.lines
+	if len(renames) > 0 {
+		r := strings.NewReplacer(renames...)
+		lines = make([]string, len(body.lines))
+		for i, line := range body.lines {
+			lines[i] = r.Replace(line)
+		}
+	}
+
+	s, start := s.command([]any{s.echo(), commands.Group(title)})
+	s, end := s.command([]any{s.echo(), commands.EndGroup()})
+
+	s = s.add(start)
+	s = s.add(lines...)
+	return s.add(end)
+}
+
+// Retry runs the command up to attempts times, waiting delay after every failure
+func (s Script[S]) Retry(attempts int, delay time.Duration, command ...any) Script[S] {
+	s, line := s.command(command)
+	seconds := int(delay.Round(time.Second).Seconds())
+
+	if s.shell() == gocto.ShellPwsh {
+		return s.add(
+			fmt.Sprintf("for ($attempt = 1; $attempt -le %d; $attempt++) {", attempts),
+			"  try {",
+			"    "+line,
+			"    break",
+			"  } catch {",
+			fmt.Sprintf("    if ($attempt -eq %d) { throw }", attempts),
+			fmt.Sprintf(`    Write-Warning "attempt $attempt failed, retrying in %ds"`, seconds),
+			fmt.Sprintf("    Start-Sleep -Seconds %d", seconds),
+			"  }",
+			"}",
+		)
+	}
+
+	return s.add(
+		fmt.Sprintf("for attempt in $(seq 1 %d); do", attempts),
+		"  "+line+" && break",
+		fmt.Sprintf(`  if [ "$attempt" -eq %d ]; then exit 1; fi`, attempts),
+		fmt.Sprintf(`  echo "attempt $attempt failed, retrying in %ds" >&2`, seconds),
+		fmt.Sprintf("  sleep %d", seconds),
+		"done",
+	)
+}
+
+// String renders the script, starting with the settings which make it fail on the first error
+func (s Script[S]) String() string {
+	var b strings.Builder
+	if s.shell() == gocto.ShellPwsh {
+		b.WriteString("$ErrorActionPreference = 'Stop'\n")
+		b.WriteString("$PSNativeCommandUseErrorActionPreference = $true\n")
+	} else {
+		b.WriteString("set -euo pipefail\n")
+	}
+
+	for _, line := range s.lines {
+		line = exprRefPattern.ReplaceAllStringFunc(line, func(ref string) string {
+			return s.ref(EnvVar(strings.Trim(ref, "\x00")))
+		})
+		b.WriteString(line + "\n")
+	}
+
+	return b.String()
+}
+
+// Env returns the environment variables passing the expressions used by the script
+func (s Script[S]) Env() map[string]string {
+	if len(s.env) == 0 {
+		return nil
+	}
+
+	env := make(map[string]string, len(s.env))
+	for name, expr := range s.env {
+		env[name] = expr.String()
+	}
+
+	return env
+}
+
+// Step returns a run step for the script, the multi-line script renders as a YAML literal block
+func (s Script[S]) Step() gocto.Step {
+	return gocto.Step{
+		Shell: s.shell(),
+		Run:   s.String(),
+		Env:   s.Env(),
+	}
+}
+
+func (s Script[S]) echo() string {
+	if s.shell() == gocto.ShellPwsh {
+		return "Write-Output"
+	}
+
+	return "echo"
+}
+
+func (s Script[S]) command(words []any) (Script[S], string) {
+	quoted := make([]string, 0, len(words))
+	for i, w := range words {
+		var q string
+		s, q = s.word(w)
+		// pwsh only runs a quoted program with the call operator
+		if i == 0 && s.shell() == gocto.ShellPwsh && !s.unquoted(q) {
+			q = "& " + q
+		}
+		quoted = append(quoted, q)
+	}
+
+	return s, strings.Join(quoted, " ")
+}
+
+// unquoted reports whether word is safe to leave unquoted in the shell of the script
+func (s Script[S]) unquoted(word string) bool {
+	if s.shell() == gocto.ShellPwsh {
+		return util.PwshUnquoted(word)
+	}
+
+	return util.BashUnquoted(word)
+}
+
+// word quotes a single word, expressions become environment variables of the script
+func (s Script[S]) word(w any) (Script[S], string) {
+	switch w := w.(type) {
+	case string:
+		return s, s.quote(w)
+	case concat:
+		var parts []string
+		for _, p := range w {
+			switch p := p.(type) {
+			case expressions.Expression:
+				var name EnvVar
+				s, name = s.exprVar(p)
+				parts = append(parts, exprRef(name))
+			case EnvVar:
+				parts = append(parts, s.ref(p))
+			default:
+				parts = append(parts, s.escapeDoubleQuoted(fmt.Sprint(p)))
+			}
+		}
+		return s, `"` + strings.Join(parts, "") + `"`
+	case expressions.Expression:
+		s, name := s.exprVar(w)
+		return s, `"` + exprRef(name) + `"`
+	case EnvVar:
+		return s, `"` + s.ref(w) + `"`
+	}
+
+	return s, s.quote(fmt.Sprint(w))
+}
+
+// exprVar returns the environment variable passing expr, adding it to the script
+func (s Script[S]) exprVar(expr expressions.Expression) (Script[S], EnvVar) {
+	name := envName(expr)
+	for n := 2; s.env[name] != "" && s.env[name] != expr; n++ {
+		name = fmt.Sprintf("%s_%d", envName(expr), n)
+	}
+
+	return s.withEnv(map[string]expressions.Expression{name: expr}), EnvVar(name)
+}
+
+// exprRef stands for the reference to the variable passing an expression until the script is rendered,
+// so Group can rename it. A shell script can't hold NUL bytes, so no literal is mistaken for one.
+func exprRef(name EnvVar) string {
+	return "\x00" + string(name) + "\x00"
+}
+
+var exprRefPattern = regexp.MustCompile("\x00[A-Za-z0-9_]+\x00")
+
+// ref is the reference to an environment variable within double quotes
+func (s Script[S]) ref(name EnvVar) string {
+	if s.shell() == gocto.ShellPwsh {
+		return "$($env:" + string(name) + ")"
+	}
+
+	return "${" + string(name) + "}"
+}
+
+func (s Script[S]) withEnv(env map[string]expressions.Expression) Script[S] {
+	if len(env) == 0 {
+		return s
+	}
+
+	s.env = maps.Clone(s.env)
+	if s.env == nil {
+		s.env = make(map[string]expressions.Expression)
+	}
+
+	maps.Copy(s.env, env)
+	return s
+}
+
+func (s Script[S]) quote(literal string) string {
+	if s.shell() == gocto.ShellPwsh {
+		return escapeExpressions(util.PwshQuote(literal))
+	}
+
+	return escapeExpressions(util.BashQuote(literal))
+}
+
+func (s Script[S]) escapeDoubleQuoted(literal string) string {
+	if s.shell() == gocto.ShellPwsh {
+		return escapeExpressions(strings.NewReplacer("`", "``", `$`, "`$", `"`, "`\"").Replace(literal))
+	}
+
+	return escapeExpressions(strings.NewReplacer(`\`, `\\`, `$`, `\$`, "`", "\\`", `"`, `\"`).Replace(literal))
+}
+
+// escapeExpressions keeps GitHub from evaluating ${{ in quoted literals before the script runs
+func escapeExpressions(literal string) string {
+	return strings.ReplaceAll(literal, "${{", "${{ '${{' }}")
+}
+
+var nonWord = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// envName derives the variable of an expression from it, e.g. NEEDS_BUILD_OUTPUTS_VERSION
+func envName(expr expressions.Expression) string {
+	name := strings.Trim(nonWord.ReplaceAllString(strings.ToUpper(string(expr)), "_"), "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "EXPR_" + name
+	}
+
+	return name
+}
@@ -0,0 +1,129 @@
+package script
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cakehappens/gocto"
+	"github.com/cakehappens/gocto/expressions"
+)
+
+func TestBash(t *testing.T) {
+	version := expressions.NeedsOutput("build", "version")
+
+	step := Bash().
+		Run("echo", "it's", "$HOME", "a b", "${{ github.sha }}").
+		Group("Deploy", Bash().Run("deploy", "--version", version, Concat("--tag=app:", version, "-", EnvVar("GITHUB_SHA")))).
+		Heredoc("config.json", "{\"version\": \"$VERSION\"}\nEOF\n").
+		Retry(3, 10*time.Second, "curl", "-f", "https://example.com").
+		Step()
+
+	expected := `set -euo pipefail
+echo 'it'\''s' '$HOME' 'a b' '${{ '${{' }} github.sha }}'
+echo ::group::Deploy
+deploy --version "${NEEDS_BUILD_OUTPUTS_VERSION}" "--tag=app:${NEEDS_BUILD_OUTPUTS_VERSION}-${GITHUB_SHA}"
+echo ::endgroup::
+cat > config.json <<'EOF_'
+{"version": "$VERSION"}
+EOF
+EOF_
+for attempt in $(seq 1 3); do
+  curl -f https://example.com && break
+  if [ "$attempt" -eq 3 ]; then exit 1; fi
+  echo "attempt $attempt failed, retrying in 10s" >&2
+  sleep 10
+done
+`
+
+	assert.Equal(t, expected, step.Run)
+	assert.Equal(t, gocto.ShellBash, step.Shell)
+	assert.Equal(t, map[string]string{"NEEDS_BUILD_OUTPUTS_VERSION": "${{needs.build.outputs.version}}"}, step.Env)
+
+	yaml, err := gocto.MarshalYAML(step)
+	require.NoError(t, err)
+	assert.Contains(t, string(yaml), "run: |\n  set -euo pipefail\n")
+}
+
+func TestBashRuns(t *testing.T) {
+	dir := t.TempDir()
+	s := Bash().
+		Run("printf", "%s|", "it's", "$HOME", "a b", `"quoted"`, Concat(`$x "y" `, EnvVar("VALUE"))).
+		Heredoc(filepath.Join(dir, "out"), "$HOME `date`\n")
+
+	cmd := exec.Command("bash", "-c", s.String())
+	cmd.Env = []string{"VALUE=v a l", "PATH=" + os.Getenv("PATH")}
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+	assert.Equal(t, `it's|$HOME|a b|"quoted"|$x "y" v a l|`, string(out))
+
+	written, err := os.ReadFile(filepath.Join(dir, "out"))
+	require.NoError(t, err)
+	assert.Equal(t, "$HOME `date`\n", string(written))
+}
+
+func TestPwsh(t *testing.T) {
+	step := Pwsh().
+		Run("Write-Output", "it's", Concat("v", expressions.Inputs("version"), "$")).
+		Run("Write-Output", "a,b", "@splat").
+		Retry(2, 5*time.Second, "./publish.ps1").
+		Step()
+
+	expected := `$ErrorActionPreference = 'Stop'
+$PSNativeCommandUseErrorActionPreference = $true
+Write-Output 'it''s' "v$($env:INPUTS_VERSION)` + "`$" + `"
+Write-Output 'a,b' '@splat'
+for ($attempt = 1; $attempt -le 2; $attempt++) {
+  try {
+    ./publish.ps1
+    break
+  } catch {
+    if ($attempt -eq 2) { throw }
+    Write-Warning "attempt $attempt failed, retrying in 5s"
+    Start-Sleep -Seconds 5
+  }
+}
+`
+
+	assert.Equal(t, expected, step.Run)
+	assert.Equal(t, gocto.ShellPwsh, step.Shell)
+	assert.Equal(t, map[string]string{"INPUTS_VERSION": "${{inputs.version}}"}, step.Env)
+}
+
+func TestPwshHeredoc(t *testing.T) {
+	s := Pwsh().
+		Heredoc("notes.txt", "it's\n@'\n").
+		Heredoc("quote.ps1", "$s = @'\nit's\n'@ | Write-Output\n")
+
+	expected := `$ErrorActionPreference = 'Stop'
+$PSNativeCommandUseErrorActionPreference = $true
+@'
+it's
+@'
+'@ | Set-Content -Path notes.txt
+Set-Content -Path quote.ps1 -Value '$s = @''
+it''s
+''@ | Write-Output'
+`
+
+	assert.Equal(t, expected, s.String())
+}
+
+func TestGroupEnvCollision(t *testing.T) {
+	s := Bash().
+		Run("echo", expressions.From("inputs.a-b")).
+		Group("Body", Bash().Run("echo", expressions.From("inputs.a.b"), expressions.From("inputs.a-b")))
+
+	assert.Equal(t, `set -euo pipefail
+echo "${INPUTS_A_B}"
+echo ::group::Body
+echo "${INPUTS_A_B_2}" "${INPUTS_A_B}"
+echo ::endgroup::
+`, s.String())
+	assert.Equal(t, map[string]string{"INPUTS_A_B": "${{inputs.a-b}}", "INPUTS_A_B_2": "${{inputs.a.b}}"}, s.Env())
+}
@@ -0,0 +1,27 @@
+package gocto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cakehappens/gocto/expressions"
+)
+
+func TestGoRunStep(t *testing.T) {
+	step := GoRunStep("./cmd/release", map[string]expressions.Expression{
+		"channel": expressions.FromTemplate("stable"),
+		"version": expressions.From("github.ref_name"),
+	})
+
+	assert.Equal(t, Step{
+		Run:   "go run ./cmd/release",
+		Shell: ShellBash,
+		Env: map[string]string{
+			"INPUT_CHANNEL": "stable",
+			"INPUT_VERSION": "${{github.ref_name}}",
+		},
+	}, step)
+
+	assert.Equal(t, "go run './tools/it'\\''s here'", GoRunStep("./tools/it's here", nil).Run)
+}
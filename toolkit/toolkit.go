@@ -0,0 +1,203 @@
+// Package toolkit is for steps written in Go, like @actions/core is for JavaScript actions.
+//
+// It reads the inputs of the step and talks to the runner, through workflow commands
+// written to Stdout and the files the runner gives the step, e.g. $GITHUB_OUTPUT.
+// gocto.GoRunStep builds the step running such a program.
+//
+//	func main() {
+//		name, err := toolkit.RequireInput("name")
+//		if err != nil {
+//			toolkit.SetFailed(err)
+//		}
+//
+//		if err := toolkit.SetOutput("greeting", "hello "+name); err != nil {
+//			toolkit.SetFailed(err)
+//		}
+//	}
+package toolkit
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cakehappens/gocto/commands"
+)
+
+// Stdout is where the workflow commands are written, os.Stdout by default
+var Stdout io.Writer = os.Stdout
+
+// exit ends the program for SetFailed, replaced by tests
+var exit = os.Exit
+
+// InputVar is the environment variable passing the input name to the step, e.g. INPUT_DRY-RUN for dry-run
+func InputVar(name string) string {
+	return "INPUT_" + strings.ToUpper(strings.ReplaceAll(name, " ", "_"))
+}
+
+// GetInput returns the input name with surrounding whitespace trimmed, or "" when it isn't set
+func GetInput(name string) string {
+	return strings.TrimSpace(os.Getenv(InputVar(name)))
+}
+
+// RequireInput is like GetInput, but an empty input is an error
+func RequireInput(name string) (string, error) {
+	value := GetInput(name)
+	if value == "" {
+		return "", fmt.Errorf("input required and not supplied: %s", name)
+	}
+
+	return value, nil
+}
+
+// GetBooleanInput parses the input as a YAML 1.2 boolean, e.g. true, True or TRUE
+func GetBooleanInput(name string) (bool, error) {
+	switch GetInput(name) {
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	}
+
+	return false, fmt.Errorf("input does not meet YAML 1.2 \"Core Schema\" specification: %s", name)
+}
+
+// GetMultilineInput returns the lines of the input, without the empty ones
+func GetMultilineInput(name string) []string {
+	var lines []string
+	for _, line := range strings.Split(GetInput(name), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines
+}
+
+// SetOutput sets the output name of the step
+func SetOutput(name, value string) error {
+	return writeKeyValue("GITHUB_OUTPUT", name, value)
+}
+
+// ExportVariable sets the environment variable for the following steps, and for this program
+func ExportVariable(name, value string) error {
+	if err := os.Setenv(name, value); err != nil {
+		return err
+	}
+
+	return writeKeyValue("GITHUB_ENV", name, value)
+}
+
+// AddPath prepends dir to PATH for the following steps, and for this program
+func AddPath(dir string) error {
+	if err := appendFile("GITHUB_PATH", dir+"\n"); err != nil {
+		return err
+	}
+
+	return os.Setenv("PATH", dir+string(filepath.ListSeparator)+os.Getenv("PATH"))
+}
+
+// SaveState keeps a value for the post step of the action, which reads it with GetState
+func SaveState(name, value string) error {
+	return writeKeyValue("GITHUB_STATE", name, value)
+}
+
+// GetState returns the value saved by SaveState in the main step
+func GetState(name string) string {
+	return os.Getenv("STATE_" + name)
+}
+
+// SetSecret masks value in the rest of the log
+func SetSecret(value string) {
+	issue(commands.AddMask(value))
+}
+
+// IsDebug is true when the run has debug logging enabled
+func IsDebug() bool {
+	return os.Getenv("RUNNER_DEBUG") == "1"
+}
+
+func Debug(message string) {
+	issue(commands.Debug(message))
+}
+
+func Info(message string) {
+	issue(message)
+}
+
+func Error(message string, props commands.AnnotationProperties) {
+	issue(commands.Error(message, props))
+}
+
+func Warning(message string, props commands.AnnotationProperties) {
+	issue(commands.Warning(message, props))
+}
+
+func Notice(message string, props commands.AnnotationProperties) {
+	issue(commands.Notice(message, props))
+}
+
+// SetFailed reports err as an error annotation and exits with status 1
+func SetFailed(err error) {
+	Error(err.Error(), commands.AnnotationProperties{})
+	exit(1)
+}
+
+func StartGroup(title string) {
+	issue(commands.Group(title))
+}
+
+func EndGroup() {
+	issue(commands.EndGroup())
+}
+
+// Group folds the output of fn in the log under title
+func Group(title string, fn func() error) error {
+	StartGroup(title)
+	defer EndGroup()
+	return fn()
+}
+
+// AppendSummary adds the Markdown to the summary of the run
+func AppendSummary(summary commands.Summary) error {
+	return appendFile("GITHUB_STEP_SUMMARY", summary.String())
+}
+
+func issue(command string) {
+	_, _ = fmt.Fprintln(Stdout, command)
+}
+
+// writeKeyValue appends name and value to the file, in the heredoc form which allows multi-line values
+func writeKeyValue(fileVar, name, value string) error {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return err
+	}
+
+	delimiter := "ghadelimiter_" + hex.EncodeToString(random)
+	if strings.Contains(name, delimiter) || strings.Contains(value, delimiter) {
+		return fmt.Errorf("%s or its value contains the delimiter %s", name, delimiter)
+	}
+
+	return appendFile(fileVar, name+"<<"+delimiter+"\n"+value+"\n"+delimiter+"\n")
+}
+
+func appendFile(fileVar, content string) error {
+	filename := os.Getenv(fileVar)
+	if filename == "" {
+		return fmt.Errorf("%s is not set, not running in a workflow step", fileVar)
+	}
+
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.WriteString(content)
+	return errors.Join(err, f.Close())
+}
@@ -0,0 +1,106 @@
+package toolkit
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cakehappens/gocto/commands"
+)
+
+func captureStdout(t *testing.T) *bytes.Buffer {
+	var out bytes.Buffer
+	Stdout = &out
+	t.Cleanup(func() { Stdout = os.Stdout })
+	return &out
+}
+
+func TestInputs(t *testing.T) {
+	t.Setenv("INPUT_NAME", "  gopher \n")
+	t.Setenv("INPUT_DRY-RUN", "True")
+	t.Setenv("INPUT_MY_INPUT", "yes")
+	t.Setenv("INPUT_FILES", "a.go\n\n  b.go  \n")
+
+	assert.Equal(t, "gopher", GetInput("name"))
+	assert.Equal(t, "", GetInput("missing"))
+	assert.Equal(t, []string{"a.go", "b.go"}, GetMultilineInput("files"))
+
+	_, err := RequireInput("missing")
+	assert.EqualError(t, err, "input required and not supplied: missing")
+
+	dryRun, err := GetBooleanInput("dry-run")
+	require.NoError(t, err)
+	assert.True(t, dryRun)
+
+	_, err = GetBooleanInput("my input")
+	assert.EqualError(t, err, `input does not meet YAML 1.2 "Core Schema" specification: my input`)
+}
+
+func TestFileCommands(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"GITHUB_OUTPUT", "GITHUB_ENV", "GITHUB_PATH", "GITHUB_STATE", "GITHUB_STEP_SUMMARY"} {
+		t.Setenv(name, filepath.Join(dir, name))
+	}
+	t.Setenv("PATH", "/usr/bin")
+	t.Setenv("GOCTO_TOOLKIT_TEST", "")
+
+	require.NoError(t, SetOutput("version", "1.2.3"))
+	require.NoError(t, SetOutput("notes", "line 1\nline 2"))
+	require.NoError(t, ExportVariable("GOCTO_TOOLKIT_TEST", "on"))
+	require.NoError(t, AddPath("/opt/bin"))
+	require.NoError(t, SaveState("pid", "42"))
+	require.NoError(t, AppendSummary(commands.Summary{}.Heading(1, "Done")))
+
+	assert.Equal(t, "on", os.Getenv("GOCTO_TOOLKIT_TEST"))
+	assert.Equal(t, "/opt/bin"+string(filepath.ListSeparator)+"/usr/bin", os.Getenv("PATH"))
+
+	read := func(name string) string {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		// the delimiters are random
+		return regexp.MustCompile(`ghadelimiter_[0-9a-f]{32}`).ReplaceAllString(string(content), "EOF")
+	}
+
+	assert.Equal(t, "version<<EOF\n1.2.3\nEOF\nnotes<<EOF\nline 1\nline 2\nEOF\n", read("GITHUB_OUTPUT"))
+	assert.Equal(t, "GOCTO_TOOLKIT_TEST<<EOF\non\nEOF\n", read("GITHUB_ENV"))
+	assert.Equal(t, "/opt/bin\n", read("GITHUB_PATH"))
+	assert.Equal(t, "pid<<EOF\n42\nEOF\n", read("GITHUB_STATE"))
+	assert.Equal(t, "# Done\n", read("GITHUB_STEP_SUMMARY"))
+
+	t.Setenv("GITHUB_OUTPUT", "")
+	assert.EqualError(t, SetOutput("version", "1.2.3"), "GITHUB_OUTPUT is not set, not running in a workflow step")
+
+	t.Setenv("STATE_pid", "42")
+	assert.Equal(t, "42", GetState("pid"))
+}
+
+func TestCommands(t *testing.T) {
+	out := captureStdout(t)
+	var code int
+	exit = func(c int) { code = c }
+	t.Cleanup(func() { exit = os.Exit })
+
+	SetSecret("s3cr3t")
+	Info("building")
+	Warning("deprecated", commands.AnnotationProperties{File: "main.go", StartLine: 3})
+	err := Group("Test", func() error {
+		Debug("50% done")
+		return errors.New("tests failed")
+	})
+	SetFailed(err)
+
+	assert.Equal(t, "::add-mask::s3cr3t\n"+
+		"building\n"+
+		"::warning file=main.go,line=3::deprecated\n"+
+		"::group::Test\n"+
+		"::debug::50%25 done\n"+
+		"::endgroup::\n"+
+		"::error::tests failed\n", out.String())
+	assert.Equal(t, 1, code)
+}
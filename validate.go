@@ -0,0 +1,246 @@
+package gocto
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// https://json.schemastore.org/github-workflow.json
+//
+//go:embed github-workflow.json
+var workflowSchemaJSON []byte
+
+const workflowSchemaURL = "https://json.schemastore.org/github-workflow.json"
+
+var workflowSchema = sync.OnceValues(func() (*jsonschema.Schema, error) {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(workflowSchemaJSON))
+	if err != nil {
+		return nil, err
+	}
+
+	c := jsonschema.NewCompiler()
+	if err := c.AddResource(workflowSchemaURL, doc); err != nil {
+		return nil, err
+	}
+
+	return c.Compile(workflowSchemaURL)
+})
+
+// SchemaError is a single JSON schema failure, located by the Go field path
+// of the offending value, e.g. Jobs["build"].Steps[3].With
+type SchemaError struct {
+	Path string
+	// Pointer is the JSON pointer of the offending value in the rendered document
+	Pointer string
+	Message string
+}
+
+func (e *SchemaError) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+// Validate checks the rendered workflow against the official JSON schema,
+// every failure is returned as a *SchemaError joined with errors.Join.
+// Invalid trigger filters are returned as *FilterError.
+func (w Workflow) Validate() error {
+	var filterErrs []error
+	filterPaths := make(map[string]bool)
+	for _, e := range w.On.validateFilters() {
+		filterErrs = append(filterErrs, e)
+		filterPaths[e.Path] = true
+	}
+
+	sch, err := workflowSchema()
+	if err != nil {
+		return fmt.Errorf("compiling workflow schema: %w", err)
+	}
+
+	wfJson, err := json.Marshal(w)
+	if err != nil {
+		return err
+	}
+
+	inst, err := jsonschema.UnmarshalJSON(bytes.NewReader(wfJson))
+	if err != nil {
+		return err
+	}
+
+	err = sch.Validate(inst)
+
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return errors.Join(append([]error{err}, filterErrs...)...)
+	}
+
+	printer := message.NewPrinter(language.English)
+
+	var errs []error
+	seen := make(map[string]bool)
+	for _, leaf := range schemaLeaves(validationErr) {
+		schemaErr := &SchemaError{
+			Path:    goFieldPath(reflect.TypeFor[Workflow](), leaf.InstanceLocation),
+			Pointer: jsonPointer(leaf.InstanceLocation),
+			Message: leaf.ErrorKind.LocalizedString(printer),
+		}
+
+		// the filter errors say the same more clearly
+		if filterPaths[schemaErr.Path] {
+			continue
+		}
+
+		if key := schemaErr.Error(); !seen[key] {
+			seen[key] = true
+			errs = append(errs, schemaErr)
+		}
+	}
+
+	slices.SortStableFunc(errs, func(a, b error) int {
+		return strings.Compare(a.(*SchemaError).Pointer, b.(*SchemaError).Pointer)
+	})
+
+	return errors.Join(append(errs, filterErrs...)...)
+}
+
+// schemaLeaves flattens the error tree, only the leaves say what is actually wrong.
+// When none of the alternatives of a oneOf or anyOf matched, only the alternatives
+// which got furthest into the document are kept, the others are just noise
+// (a normal job is not a reusable workflow job, a permissions object is not a string, etc.)
+func schemaLeaves(err *jsonschema.ValidationError) []*jsonschema.ValidationError {
+	if len(err.Causes) == 0 {
+		return []*jsonschema.ValidationError{err}
+	}
+
+	causes := make([][]*jsonschema.ValidationError, 0, len(err.Causes))
+	for _, c := range err.Causes {
+		causes = append(causes, schemaLeaves(c))
+	}
+
+	switch err.ErrorKind.(type) {
+	case *kind.OneOf, *kind.AnyOf:
+		deepest := 0
+		for _, leaves := range causes {
+			deepest = max(deepest, maxDepth(leaves))
+		}
+
+		causes = slices.DeleteFunc(causes, func(leaves []*jsonschema.ValidationError) bool {
+			return maxDepth(leaves) < deepest
+		})
+	}
+
+	return slices.Concat(causes...)
+}
+
+func maxDepth(leaves []*jsonschema.ValidationError) int {
+	depth := 0
+	for _, leaf := range leaves {
+		depth = max(depth, len(leaf.InstanceLocation))
+	}
+
+	return depth
+}
+
+func jsonPointer(tokens []string) string {
+	var sb strings.Builder
+	for _, tok := range tokens {
+		sb.WriteByte('/')
+		sb.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(tok))
+	}
+
+	return sb.String()
+}
+
+// goFieldPath maps the tokens of a JSON pointer onto the Go fields of t, following json struct tags
+func goFieldPath(t reflect.Type, tokens []string) string {
+	root := t.Name()
+
+	var sb strings.Builder
+	for _, tok := range tokens {
+		for t != nil && t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+
+		switch {
+		case t == nil || t.Kind() == reflect.Interface:
+			t = nil
+			fmt.Fprintf(&sb, "[%q]", tok)
+		case t == reflect.TypeFor[Matrix]():
+			switch tok {
+			case "include", "exclude":
+				sb.WriteString("." + strings.ToUpper(tok[:1]) + tok[1:])
+				t = reflect.TypeFor[[]map[string]any]()
+			default:
+				fmt.Fprintf(&sb, ".Map[%q]", tok)
+				t = reflect.TypeFor[[]any]()
+			}
+		case t == reflect.TypeFor[Secrets]():
+			fmt.Fprintf(&sb, ".Map[%q]", tok)
+			t = nil
+		case t.Kind() == reflect.Struct:
+			field, ok := jsonField(t, tok)
+			if !ok {
+				t = nil
+				fmt.Fprintf(&sb, "[%q]", tok)
+				continue
+			}
+
+			sb.WriteString("." + field.Name)
+			t = field.Type
+		case t.Kind() == reflect.Map:
+			fmt.Fprintf(&sb, "[%q]", tok)
+			t = t.Elem()
+		case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+			if _, err := strconv.Atoi(tok); err == nil {
+				fmt.Fprintf(&sb, "[%s]", tok)
+			} else {
+				fmt.Fprintf(&sb, "[%q]", tok)
+			}
+			t = t.Elem()
+		default:
+			t = nil
+			fmt.Fprintf(&sb, "[%q]", tok)
+		}
+	}
+
+	if sb.Len() == 0 {
+		return root
+	}
+
+	return strings.TrimPrefix(sb.String(), ".")
+}
+
+// jsonField finds the field, possibly promoted from an embedded struct, encoding/json uses for name
+func jsonField(t reflect.Type, name string) (reflect.StructField, bool) {
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+
+		tagName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if tagName == "-" {
+			continue
+		}
+
+		if tagName == "" {
+			tagName = field.Name
+		}
+
+		if tagName == name {
+			return field, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
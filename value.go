@@ -0,0 +1,229 @@
+package gocto
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/cakehappens/gocto/expressions"
+	"github.com/cakehappens/gocto/internal/util"
+)
+
+// IntOrExpression is a number, or an expression evaluated when the workflow runs, e.g. ${{ matrix.timeout }}.
+// Expression takes precedence when both are set.
+type IntOrExpression struct {
+	Value      int
+	Expression expressions.Expression
+}
+
+func IntValue(v int) IntOrExpression {
+	return IntOrExpression{Value: v}
+}
+
+func IntExpression(expr expressions.Expression) IntOrExpression {
+	return IntOrExpression{Expression: expr}
+}
+
+func (x IntOrExpression) IsZero() bool {
+	return x == IntOrExpression{}
+}
+
+// Resolve returns the value, evaluating the expression with ev
+func (x IntOrExpression) Resolve(ev *expressions.Evaluator) (int, error) {
+	if x.Expression == "" {
+		return x.Value, nil
+	}
+
+	v, err := ev.Evaluate(string(x.Expression))
+	if err != nil {
+		return 0, err
+	}
+
+	return int(expressions.ToNumber(v)), nil
+}
+
+func (x *IntOrExpression) UnmarshalJSON(data []byte) error {
+	if util.IsJSONNull(data) {
+		return nil
+	}
+
+	var v int
+	if err := json.Unmarshal(data, &v); err == nil {
+		*x = IntOrExpression{Value: v}
+		return nil
+	}
+
+	expr, err := unmarshalExpression(data)
+	if err != nil {
+		return fmt.Errorf("expected a number or an expression, got %s", data)
+	}
+
+	*x = IntOrExpression{Expression: expr}
+	return nil
+}
+
+func (x IntOrExpression) MarshalJSON() ([]byte, error) {
+	if x.Expression != "" {
+		return json.Marshal(x.Expression.String())
+	}
+
+	return json.Marshal(x.Value)
+}
+
+// BoolOrExpression is a boolean, or an expression evaluated when the workflow runs, e.g. ${{ matrix.experimental }}.
+// Expression takes precedence when both are set. The zero value is unset and not rendered,
+// so a field defaulting to true, like fail-fast, can be turned off with BoolValue(false).
+type BoolOrExpression struct {
+	Value      *bool
+	Expression expressions.Expression
+}
+
+func BoolValue(v bool) BoolOrExpression {
+	return BoolOrExpression{Value: &v}
+}
+
+func BoolExpression(expr expressions.Expression) BoolOrExpression {
+	return BoolOrExpression{Expression: expr}
+}
+
+func (x BoolOrExpression) IsZero() bool {
+	return x == BoolOrExpression{}
+}
+
+// Resolve returns the value, evaluating the expression with ev, or def when unset
+func (x BoolOrExpression) Resolve(ev *expressions.Evaluator, def bool) (bool, error) {
+	if x.Expression == "" {
+		if x.Value == nil {
+			return def, nil
+		}
+
+		return *x.Value, nil
+	}
+
+	v, err := ev.Evaluate(string(x.Expression))
+	if err != nil {
+		return false, err
+	}
+
+	return expressions.IsTruthy(v), nil
+}
+
+func (x *BoolOrExpression) UnmarshalJSON(data []byte) error {
+	if util.IsJSONNull(data) {
+		return nil
+	}
+
+	var v bool
+	if err := json.Unmarshal(data, &v); err == nil {
+		*x = BoolOrExpression{Value: &v}
+		return nil
+	}
+
+	expr, err := unmarshalExpression(data)
+	if err != nil {
+		return fmt.Errorf("expected a boolean or an expression, got %s", data)
+	}
+
+	*x = BoolOrExpression{Expression: expr}
+	return nil
+}
+
+func (x BoolOrExpression) MarshalJSON() ([]byte, error) {
+	if x.Expression != "" {
+		return json.Marshal(x.Expression.String())
+	}
+
+	if x.Value == nil {
+		return []byte(util.JSONNull), nil
+	}
+
+	return json.Marshal(*x.Value)
+}
+
+// Ptr is a value for the optional fields of the model and of the steps, e.g. Required, which are nil when unset
+func Ptr[T any](v T) *T {
+	return &v
+}
+
+// unmarshalExpression accepts a string holding an expression, a plain string is not a valid value
+func unmarshalExpression(data []byte) (expressions.Expression, error) {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return "", err
+	}
+
+	if !strings.Contains(s, "${{") {
+		return "", fmt.Errorf("%q is not an expression", s)
+	}
+
+	return expressions.FromTemplate(s), nil
+}
+
+// scalarsAsText rewrites the members key of the object data, which are strings in the model, e.g. env,
+// so the numbers and booleans YAML allows unquoted, e.g. RETRIES: 3, are read as their text.
+// A member is a scalar, e.g. default: 2, or a mapping of scalars, e.g. env: {RETRIES: 3}.
+func scalarsAsText(data []byte, keys ...string) ([]byte, error) {
+	asText := func(_ string, value json.RawMessage) (json.RawMessage, error) {
+		if isBoolText(string(value)) || isNumberText(string(value)) {
+			return json.Marshal(string(value))
+		}
+		return value, nil
+	}
+
+	return util.MapMembers(data, func(key string, value json.RawMessage) (json.RawMessage, error) {
+		if !slices.Contains(keys, key) {
+			return value, nil
+		}
+
+		if bytes.HasPrefix(value, []byte("{")) {
+			return util.MapMembers(value, asText)
+		}
+		return asText(key, value)
+	})
+}
+
+// textAsScalars is the inverse of scalarsAsText for the member key of the object data:
+// the strings unquote reports are rendered as the number or boolean they hold.
+func textAsScalars(data []byte, key string, unquote func(text string) bool) ([]byte, error) {
+	asScalar := func(_ string, value json.RawMessage) (json.RawMessage, error) {
+		var text string
+		if err := json.Unmarshal(value, &text); err == nil && unquote(text) {
+			return json.RawMessage(text), nil
+		}
+		return value, nil
+	}
+
+	return util.MapMembers(data, func(k string, value json.RawMessage) (json.RawMessage, error) {
+		switch {
+		case k != key:
+			return value, nil
+		case bytes.HasPrefix(value, []byte("{")):
+			return util.MapMembers(value, asScalar)
+		}
+		return asScalar(k, value)
+	})
+}
+
+func isBoolText(text string) bool {
+	return text == "true" || text == "false"
+}
+
+// isNumberText reports whether text is a JSON number, e.g. 3 or 1.10
+func isNumberText(text string) bool {
+	_, err := strconv.ParseFloat(text, 64)
+	return err == nil && json.Valid([]byte(text))
+}
+
+// isCanonicalScalarText reports whether text reads back as the same text when rendered unquoted,
+// unlike e.g. 1.10 which YAML reads as the number 1.1
+func isCanonicalScalarText(text string) bool {
+	if isBoolText(text) {
+		return true
+	}
+
+	f, err := strconv.ParseFloat(text, 64)
+	return err == nil && isNumberText(text) && strconv.FormatFloat(f, 'f', -1, 64) == text
+}
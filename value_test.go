@@ -0,0 +1,126 @@
+package gocto
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cakehappens/gocto/expressions"
+)
+
+func TestOrExpressionJSON(t *testing.T) {
+	type testCase struct {
+		name     string
+		job      Job
+		expected string
+	}
+
+	testCases := []testCase{
+		{
+			name:     "values",
+			job:      Job{TimeoutMinutes: IntValue(10), ContinueOnError: BoolValue(true), Strategy: Strategy{MaxParallel: IntValue(2)}},
+			expected: `{"timeout-minutes":10,"continue-on-error":true,"strategy":{"max-parallel":2}}`,
+		},
+		{
+			name: "expressions",
+			job: Job{
+				TimeoutMinutes:  IntExpression("matrix.timeout"),
+				ContinueOnError: BoolExpression("matrix.experimental"),
+				Strategy:        Strategy{MaxParallel: IntExpression("fromJSON(vars.MAX_PARALLEL)")},
+			},
+			expected: `{"timeout-minutes":"${{matrix.timeout}}","continue-on-error":"${{matrix.experimental}}","strategy":{"max-parallel":"${{fromJSON(vars.MAX_PARALLEL)}}"}}`,
+		},
+		{
+			name:     "false",
+			job:      Job{ContinueOnError: BoolValue(false), Strategy: Strategy{FailFast: BoolValue(false)}},
+			expected: `{"continue-on-error":false,"strategy":{"fail-fast":false}}`,
+		},
+		{
+			name: "step expressions",
+			job: Job{Steps: []Step{{
+				TimeoutMinutes:  IntExpression(" inputs.t "),
+				ContinueOnError: BoolExpression(" matrix.experimental "),
+			}}},
+			expected: `{"steps":[{"timeout-minutes":"${{ inputs.t }}","continue-on-error":"${{ matrix.experimental }}"}]}`,
+		},
+		{
+			name:     "unset",
+			job:      Job{},
+			expected: `{}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := json.Marshal(tc.job)
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.expected, string(b))
+
+			var j Job
+			require.NoError(t, json.Unmarshal(b, &j))
+			assert.Equal(t, tc.job, j)
+		})
+	}
+}
+
+func TestOrExpressionUnmarshalErrors(t *testing.T) {
+	var x IntOrExpression
+	assert.EqualError(t, json.Unmarshal([]byte(`"ten"`), &x), `expected a number or an expression, got "ten"`)
+
+	var b BoolOrExpression
+	assert.EqualError(t, json.Unmarshal([]byte(`"yes"`), &b), `expected a boolean or an expression, got "yes"`)
+}
+
+func TestOrExpressionResolve(t *testing.T) {
+	ev := &expressions.Evaluator{Contexts: map[string]any{
+		"matrix": map[string]any{"timeout": "15", "experimental": true},
+	}}
+
+	timeout, err := IntExpression("matrix.timeout").Resolve(ev)
+	require.NoError(t, err)
+	assert.Equal(t, 15, timeout)
+
+	timeout, err = IntValue(5).Resolve(ev)
+	require.NoError(t, err)
+	assert.Equal(t, 5, timeout)
+
+	experimental, err := BoolExpression("matrix.experimental").Resolve(ev, false)
+	require.NoError(t, err)
+	assert.True(t, experimental)
+
+	failFast, err := BoolOrExpression{}.Resolve(ev, true)
+	require.NoError(t, err)
+	assert.True(t, failFast)
+
+	failFast, err = BoolValue(false).Resolve(ev, true)
+	require.NoError(t, err)
+	assert.False(t, failFast)
+}
+
+func TestOptionalBoolJSON(t *testing.T) {
+	type testCase struct {
+		name     string
+		input    OnDispatchInput
+		expected string
+	}
+
+	testCases := []testCase{
+		{name: "unset", input: OnDispatchInput{}, expected: `{}`},
+		{name: "true", input: OnDispatchInput{Required: Ptr(true)}, expected: `{"required":true}`},
+		{name: "false", input: OnDispatchInput{Required: Ptr(false)}, expected: `{"required":false}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := json.Marshal(tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(b))
+
+			var in OnDispatchInput
+			require.NoError(t, json.Unmarshal(b, &in))
+			assert.Equal(t, tc.input, in)
+		})
+	}
+}
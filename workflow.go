@@ -1,6 +1,7 @@
 package gocto
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -9,6 +10,7 @@ import (
 	"slices"
 	"strings"
 
+	"github.com/cakehappens/gocto/expressions"
 	"github.com/cakehappens/gocto/internal/util"
 )
 
@@ -27,6 +29,9 @@ type Workflow struct {
 	Env         map[string]string `json:"env,omitempty,omitzero"`
 	Permissions Permissions       `json:"permissions,omitempty,omitzero"`
 	Jobs        map[string]Job    `json:"jobs"`
+	// Extra holds the keys gocto has no field for, e.g. ones GitHub added since, they are rendered after the fields.
+	// The same goes for the Extra of WorkflowOn, the triggers, Job and Step.
+	Extra map[string]any `json:"-"`
 	// Storing filename here is useful when you need to reference reusable workflows
 	filename string
 }
@@ -56,6 +61,11 @@ func (w *Workflow) GetRelativePathAndFilename() string {
 }
 
 func FilenameFor(w Workflow) string {
+	return slugFor(w.Name) + ".yml"
+}
+
+// slugFor lowercases name and replaces every run of characters which are not ASCII letters or digits with a single -
+func slugFor(name string) string {
 	newName := strings.Map(func(r rune) rune {
 		switch {
 		case '0' <= r && r <= '9':
@@ -67,11 +77,11 @@ func FilenameFor(w Workflow) string {
 		default:
 			return '-'
 		}
-	}, w.Name)
+	}, name)
 
 	newName = strings.Trim(newName, "-")
 	newName = util.RemoveDupOf(newName, '-')
-	newName = strings.ToLower(newName + ".yml")
+	newName = strings.ToLower(newName)
 
 	return newName
 }
@@ -86,20 +96,47 @@ type WorkflowOn struct {
 	PullRequest       *OnPullRequest `json:"pull_request,omitempty"`
 	PullRequestTarget *OnPullRequest `json:"pull_request_target,omitempty,omitzero"`
 	Push              *OnPush        `json:"push,omitempty,omitzero"`
+	Extra             map[string]any `json:"-"`
 }
 
 type OnCall struct {
-	Inputs  map[string]CallInput  `json:"inputs,omitempty,omitzero"`
-	Outputs map[string]CallOutput `json:"outputs,omitempty,omitzero"`
+	Inputs  map[string]CallInput   `json:"inputs,omitempty,omitzero"`
+	Outputs map[string]CallOutput  `json:"outputs,omitempty,omitzero"`
+	Secrets map[string]CallSecrets `json:"secrets,omitempty,omitzero"`
+	Extra   map[string]any         `json:"-"`
 }
 
 type CallInput struct {
 	Description string        `json:"description,omitempty,omitzero"`
 	Default     string        `json:"default,omitempty,omitzero"`
-	Required    bool          `json:"required,omitempty,omitzero"`
+	Required    *bool         `json:"required,omitempty,omitzero"`
 	Type        CallInputType `json:"type,omitempty,omitzero"`
 }
 
+// UnmarshalJSON reads a number or boolean default, e.g. default: 2, as its text
+func (in *CallInput) UnmarshalJSON(data []byte) error {
+	data, err := scalarsAsText(data, "default")
+	if err != nil {
+		return err
+	}
+
+	type plain CallInput
+	return json.Unmarshal(data, (*plain)(in))
+}
+
+// MarshalJSON renders the default of a number or boolean input unquoted
+func (in CallInput) MarshalJSON() ([]byte, error) {
+	type plain CallInput
+	data, err := json.Marshal(plain(in))
+	if err != nil {
+		return nil, err
+	}
+
+	return textAsScalars(data, "default", func(text string) bool {
+		return (in.Type == CallInputTypeNumber && isNumberText(text)) || (in.Type == CallInputTypeBoolean && isBoolText(text))
+	})
+}
+
 type CallOutput struct {
 	Description string `json:"description,omitempty,omitzero"`
 	Value       string `json:"value,omitempty,omitzero"`
@@ -107,7 +144,7 @@ type CallOutput struct {
 
 type CallSecrets struct {
 	Description string `json:"description,omitempty,omitzero"`
-	Required    bool   `json:"required,omitempty,omitzero"`
+	Required    *bool  `json:"required,omitempty,omitzero"`
 }
 
 type CallInputType string
@@ -122,20 +159,46 @@ type OnWorkflowRun struct {
 	Workflows   []string `json:"workflows,omitempty,omitzero"`
 	Types       []string `json:"types,omitempty,omitzero"`
 	*OnBranches `json:",inline"`
+	Extra       map[string]any `json:"-"`
 }
 
 type OnDispatch struct {
 	Inputs map[string]OnDispatchInput `json:"inputs,omitempty,omitzero"`
+	Extra  map[string]any             `json:"-"`
 }
 
 type OnDispatchInput struct {
 	Description string              `json:"description,omitempty,omitzero"`
-	Required    bool                `json:"required"`
+	Required    *bool               `json:"required,omitempty,omitzero"`
 	Default     string              `json:"default,omitempty,omitzero"`
 	Type        OnDispatchInputType `json:"type,omitempty,omitzero"`
 	Options     []string            `json:"options,omitempty,omitzero"`
 }
 
+// UnmarshalJSON reads a number or boolean default, e.g. default: true, as its text
+func (in *OnDispatchInput) UnmarshalJSON(data []byte) error {
+	data, err := scalarsAsText(data, "default")
+	if err != nil {
+		return err
+	}
+
+	type plain OnDispatchInput
+	return json.Unmarshal(data, (*plain)(in))
+}
+
+// MarshalJSON renders the default of a number or boolean input unquoted
+func (in OnDispatchInput) MarshalJSON() ([]byte, error) {
+	type plain OnDispatchInput
+	data, err := json.Marshal(plain(in))
+	if err != nil {
+		return nil, err
+	}
+
+	return textAsScalars(data, "default", func(text string) bool {
+		return (in.Type == OnDispatchInputTypeNumber && isNumberText(text)) || (in.Type == OnDispatchInputTypeBoolean && isBoolText(text))
+	})
+}
+
 type OnDispatchInputType string
 
 const (
@@ -161,45 +224,78 @@ type OnTags struct {
 	TagsIgnore []string `json:"tags-ignore,omitempty,omitzero"`
 }
 
-type OnSchedule struct{}
+// OnSchedule runs the workflow at the times of its cron expressions, in UTC
+type OnSchedule struct {
+	Crons []string
+}
+
+func (on *OnSchedule) UnmarshalJSON(data []byte) error {
+	if util.IsJSONNull(data) {
+		return nil
+	}
+
+	var entries []struct {
+		Cron string `json:"cron"`
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+
+	*on = OnSchedule{}
+	for _, e := range entries {
+		on.Crons = append(on.Crons, e.Cron)
+	}
+
+	return nil
+}
+
+func (on OnSchedule) IsZero() bool {
+	return len(on.Crons) == 0
+}
+
+func (on OnSchedule) MarshalJSON() ([]byte, error) {
+	entries := make([]map[string]string, 0, len(on.Crons))
+	for _, cron := range on.Crons {
+		entries = append(entries, map[string]string{"cron": cron})
+	}
+
+	return json.Marshal(entries)
+}
 
 type OnPullRequest struct {
 	*OnPaths    `json:",inline"`
 	*OnBranches `json:",inline"`
+	Extra       map[string]any `json:"-"`
 }
 
 type OnPush struct {
 	*OnPaths    `json:",inline"`
 	*OnBranches `json:",inline"`
 	*OnTags     `json:",inline"`
-}
-
-// Concurrency
-// https://docs.github.com/en/actions/reference/workflow-syntax-for-github-actions#concurrency
-type Concurrency struct {
-	Group            string `json:"group,omitempty,omitzero"`
-	CancelInProgress bool   `json:"cancel-in-progress,omitempty,omitzero"`
+	Extra       map[string]any `json:"-"`
 }
 
 type Job struct {
-	Name            string            `json:"name,omitempty,omitzero"`
-	Permissions     Permissions       `json:"permissions,omitempty,omitzero"`
-	Needs           StringOrSlice     `json:"needs,omitempty,omitzero"`
-	If              string            `json:"if,omitempty,omitzero"`
-	RunsOn          StringOrSlice     `json:"runs-on,omitempty,omitzero"`
-	Environment     Environment       `json:"environment,omitempty,omitzero"`
-	Concurrency     Concurrency       `json:"concurrency,omitempty,omitzero"`
-	Outputs         map[string]string `json:"outputs,omitempty,omitzero"`
-	Env             map[string]string `json:"env,omitempty,omitzero"`
-	Defaults        Defaults          `json:"defaults,omitempty,omitzero"`
-	Steps           []Step            `json:"steps,omitempty,omitzero"`
-	TimeoutMinutes  int               `json:"timeout-minutes,omitempty,omitzero"`
-	ContinueOnError bool              `json:"continue-on-error,omitempty,omitzero"`
-	Uses            string            `json:"uses,omitempty,omitzero"`
-	With            map[string]string `json:"with,omitempty,omitzero"`
-	Secrets         *Secrets          `json:"secrets,omitempty,omitzero"`
-	Container       Container         `json:"container,omitempty,omitzero"`
-	Strategy        Strategy          `json:"strategy,omitempty,omitzero"`
+	Name            string               `json:"name,omitempty,omitzero"`
+	Permissions     Permissions          `json:"permissions,omitempty,omitzero"`
+	Needs           StringOrSlice        `json:"needs,omitempty,omitzero"`
+	If              string               `json:"if,omitempty,omitzero"`
+	RunsOn          StringOrSlice        `json:"runs-on,omitempty,omitzero"`
+	Environment     Environment          `json:"environment,omitempty,omitzero"`
+	Concurrency     Concurrency          `json:"concurrency,omitempty,omitzero"`
+	Outputs         map[string]string    `json:"outputs,omitempty,omitzero"`
+	Env             map[string]string    `json:"env,omitempty,omitzero"`
+	Defaults        Defaults             `json:"defaults,omitempty,omitzero"`
+	Steps           []Step               `json:"steps,omitempty,omitzero"`
+	TimeoutMinutes  IntOrExpression      `json:"timeout-minutes,omitempty,omitzero"`
+	ContinueOnError BoolOrExpression     `json:"continue-on-error,omitempty,omitzero"`
+	Uses            string               `json:"uses,omitempty,omitzero"`
+	With            map[string]string    `json:"with,omitempty,omitzero"`
+	Secrets         *Secrets             `json:"secrets,omitempty,omitzero"`
+	Container       Container            `json:"container,omitempty,omitzero"`
+	Services        map[string]Container `json:"services,omitempty,omitzero"`
+	Strategy        Strategy             `json:"strategy,omitempty,omitzero"`
+	Extra           map[string]any       `json:"-"`
 }
 
 type StringOrSlice []string
@@ -212,6 +308,7 @@ func (j *StringOrSlice) UnmarshalJSON(data []byte) error {
 	var stringVal string
 	if err := json.Unmarshal(data, &stringVal); err == nil {
 		*j = []string{stringVal}
+		return nil
 	}
 
 	type TmpJson StringOrSlice
@@ -279,12 +376,17 @@ func (s *Secrets) UnmarshalJSON(data []byte) error {
 		*s = Secrets{
 			Map: mapVal,
 		}
+		return nil
 	}
 
 	return errors.New("unable to unmarshal secrets field, expected string \"inherit\" or map[string]string")
 }
 
+// Permissions of the GITHUB_TOKEN, unset scopes have no access when any scope is set
 type Permissions struct {
+	// All is the access to every scope, rendered as read-all or write-all,
+	// or as {} for AccessLevelNone, which revokes every permission. The scopes are ignored when it is set.
+	All            AccessLevel `json:"-"`
 	Actions        AccessLevel `json:"actions,omitempty,omitzero"`
 	Attestations   AccessLevel `json:"attestations,omitempty,omitzero"`
 	Checks         AccessLevel `json:"checks,omitempty,omitzero"`
@@ -301,6 +403,48 @@ type Permissions struct {
 	Statuses       AccessLevel `json:"statuses,omitempty,omitzero"`
 }
 
+// UnmarshalJSON also accepts the read-all and write-all shorthands, and {} is read as All: AccessLevelNone
+func (p *Permissions) UnmarshalJSON(data []byte) error {
+	if util.IsJSONNull(data) {
+		return nil
+	}
+
+	var all string
+	if err := json.Unmarshal(data, &all); err == nil {
+		level, ok := strings.CutSuffix(all, "-all")
+		if !ok || (level != string(AccessLevelRead) && level != string(AccessLevelWrite)) {
+			return fmt.Errorf("unexpected value for permissions: %s", all)
+		}
+
+		*p = Permissions{All: AccessLevel(level)}
+		return nil
+	}
+
+	type plain Permissions
+	if err := json.Unmarshal(data, (*plain)(p)); err != nil {
+		return err
+	}
+
+	if *p == (Permissions{}) {
+		p.All = AccessLevelNone
+	}
+
+	return nil
+}
+
+func (p Permissions) MarshalJSON() ([]byte, error) {
+	if p.All == AccessLevelNone {
+		return []byte("{}"), nil
+	}
+
+	if p.All != "" {
+		return json.Marshal(string(p.All) + "-all")
+	}
+
+	type plain Permissions
+	return json.Marshal(plain(p))
+}
+
 type AccessLevel string
 
 const (
@@ -309,9 +453,53 @@ const (
 	AccessLevelNone  AccessLevel = "none"
 )
 
+// Environment
+// https://docs.github.com/en/actions/reference/workflow-syntax-for-github-actions#jobsjob_idenvironment
 type Environment struct {
-	Name string `json:"name,omitempty,omitzero"`
-	URL  string `json:"url,omitempty,omitzero"`
+	Name string
+	// URL is shown on the deployment, typically an output of the deploying step
+	URL expressions.Expression
+}
+
+func (e *Environment) UnmarshalJSON(data []byte) error {
+	if util.IsJSONNull(data) {
+		return nil
+	}
+
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*e = Environment{Name: name}
+		return nil
+	}
+
+	var obj struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+
+	*e = Environment{
+		Name: obj.Name,
+		URL:  expressions.FromTemplate(obj.URL),
+	}
+	return nil
+}
+
+// MarshalJSON uses the name shorthand when there is no URL
+func (e Environment) MarshalJSON() ([]byte, error) {
+	if e.URL == "" {
+		return json.Marshal(e.Name)
+	}
+
+	return json.Marshal(struct {
+		Name string `json:"name,omitempty"`
+		URL  string `json:"url"`
+	}{
+		Name: e.Name,
+		URL:  e.URL.Template(),
+	})
 }
 
 type Defaults struct {
@@ -333,8 +521,22 @@ type Step struct {
 	Shell            Shell             `json:"shell,omitempty,omitzero"`
 	With             map[string]any    `json:"with,omitempty,omitzero"`
 	Env              map[string]string `json:"env,omitempty,omitzero"`
-	ContinueOnError  bool              `json:"continue-on-error,omitempty,omitzero"`
-	TimeoutMinutes   int               `json:"timeout-minutes,omitempty,omitzero"`
+	ContinueOnError  BoolOrExpression  `json:"continue-on-error,omitempty,omitzero"`
+	TimeoutMinutes   IntOrExpression   `json:"timeout-minutes,omitempty,omitzero"`
+	Extra            map[string]any    `json:"-"`
+}
+
+// DisplayName is the name GitHub shows for the step: its name, else what it uses, else "Run" and the first line of the script
+func (s Step) DisplayName() string {
+	switch {
+	case s.Name != "":
+		return s.Name
+	case s.Uses != "":
+		return s.Uses
+	}
+
+	first, _, _ := strings.Cut(strings.TrimSpace(s.Run), "\n")
+	return "Run " + first
 }
 
 func (s Step) WithName(name string) Step {
@@ -348,6 +550,7 @@ func (s Step) WithID(id string) Step {
 }
 
 func (s Step) WithEnv(key, value string) Step {
+	s.Env = maps.Clone(s.Env)
 	if s.Env == nil {
 		s.Env = make(map[string]string)
 	}
@@ -356,36 +559,102 @@ func (s Step) WithEnv(key, value string) Step {
 	return s
 }
 
-type Shell string
+// WithIf sets the condition, the surrounding ${{ }} is optional for conditions and left out
+func (s Step) WithIf(expr expressions.Expression) Step {
+	s.If = string(expr)
+	return s
+}
 
-const (
-	ShellBash Shell = "bash"
-)
+func (s Step) WithWith(key string, value any) Step {
+	s.With = maps.Clone(s.With)
+	if s.With == nil {
+		s.With = make(map[string]any)
+	}
+
+	s.With[key] = value
+	return s
+}
+
+func (s Step) WithShell(shell Shell) Step {
+	s.Shell = shell
+	return s
+}
+
+func (s Step) WithTimeout(minutes int) Step {
+	s.TimeoutMinutes = IntValue(minutes)
+	return s
+}
+
+func (s Step) WithWorkingDirectory(dir string) Step {
+	s.WorkingDirectory = dir
+	return s
+}
 
+// Container is the container of a job, or a service container
+// https://docs.github.com/en/actions/reference/workflow-syntax-for-github-actions#jobsjob_idcontainer
 type Container struct {
 	Image       string               `json:"image,omitempty,omitzero"`
 	Env         map[string]string    `json:"env,omitempty,omitzero"`
-	Ports       map[string]int       `json:"ports,omitempty,omitzero"`
+	Ports       []StringOrInt        `json:"ports,omitempty,omitzero"`
 	Volumes     []string             `json:"volumes,omitempty,omitzero"`
 	Credentials ContainerCredentials `json:"credentials,omitempty,omitzero"`
 	Options     string               `json:"options,omitempty,omitzero"`
 }
 
+// UnmarshalJSON also accepts the image shorthand, e.g. container: node:18
+func (c *Container) UnmarshalJSON(data []byte) error {
+	if util.IsJSONNull(data) {
+		return nil
+	}
+
+	var image string
+	if err := json.Unmarshal(data, &image); err == nil {
+		*c = Container{Image: image}
+		return nil
+	}
+
+	data, err := scalarsAsText(data, "env")
+	if err != nil {
+		return err
+	}
+
+	type plain Container
+	return json.Unmarshal(data, (*plain)(c))
+}
+
+// MarshalJSON uses the image shorthand when there is only an image
+func (c Container) MarshalJSON() ([]byte, error) {
+	if c.Image == "" || len(c.Env) > 0 || len(c.Ports) > 0 || len(c.Volumes) > 0 || c.Credentials != (ContainerCredentials{}) || c.Options != "" {
+		type plain Container
+		return json.Marshal(plain(c))
+	}
+
+	return json.Marshal(c.Image)
+}
+
 type ContainerCredentials struct {
 	Username string `json:"username,omitempty,omitzero"`
 	Password string `json:"password,omitempty,omitzero"`
 }
 
 type Strategy struct {
-	Matrix      *Matrix `json:"matrix,omitempty,omitzero"`
-	FailFast    bool    `json:"fail-fast,omitempty,omitzero"`
-	MaxParallel int     `json:"max-parallel,omitempty,omitzero"`
+	Matrix      *Matrix          `json:"matrix,omitempty,omitzero"`
+	FailFast    BoolOrExpression `json:"fail-fast,omitempty,omitzero"`
+	MaxParallel IntOrExpression  `json:"max-parallel,omitempty,omitzero"`
 }
 
+// Matrix is the values a job runs with, or an expression giving them when the workflow runs,
+// e.g. ${{ fromJSON(needs.setup.outputs.matrix) }}
 type Matrix struct {
-	Map     map[string][]StringOrInt
-	Include []map[string]StringOrInt
-	Exclude []map[string]StringOrInt
+	// Map holds the values of each key, any JSON value, e.g. linux or {os: linux, arch: amd64}.
+	// Numbers read from a document are a json.Number, so they are rendered exactly as they were read.
+	Map map[string][]any
+	// KeyExpressions give the values of a key, or the entries of include or exclude, when the workflow runs,
+	// e.g. os: ${{ fromJSON(needs.setup.outputs.os) }}
+	KeyExpressions map[string]expressions.Expression
+	Include        []map[string]any
+	Exclude        []map[string]any
+	Expression     expressions.Expression
 }
 
 func (m *Matrix) UnmarshalJSON(data []byte) error {
@@ -393,43 +662,47 @@ func (m *Matrix) UnmarshalJSON(data []byte) error {
 		return nil
 	}
 
+	if expr, err := unmarshalExpression(data); err == nil {
+		*m = Matrix{Expression: expr}
+		return nil
+	}
+
 	rawMap := make(map[string]json.RawMessage)
 	if err := json.Unmarshal(data, &rawMap); err != nil {
 		return err
 	}
 
-	internalMap := make(map[string][]StringOrInt)
-	var internalInclude []map[string]StringOrInt
-	var internalExclude []map[string]StringOrInt
+	*m = Matrix{}
+	for _, k := range slices.Sorted(maps.Keys(rawMap)) {
+		if expr, err := unmarshalExpression(rawMap[k]); err == nil {
+			if m.KeyExpressions == nil {
+				m.KeyExpressions = make(map[string]expressions.Expression)
+			}
+			m.KeyExpressions[k] = expr
+			continue
+		}
 
-	keys := slices.Sorted(maps.Keys(rawMap))
-	for _, k := range keys {
+		// numbers stay json.Number, so they are rendered exactly as they were read
+		dec := json.NewDecoder(bytes.NewReader(rawMap[k]))
+		dec.UseNumber()
+
+		var err error
 		switch k {
 		case "include":
-			err := json.Unmarshal(rawMap[k], &internalInclude)
-			if err != nil {
-				return err
-			}
+			err = dec.Decode(&m.Include)
 		case "exclude":
-			err := json.Unmarshal(rawMap[k], &internalExclude)
-			if err != nil {
-				return err
-			}
+			err = dec.Decode(&m.Exclude)
 		default:
-			var listStringOrInt []StringOrInt
-			err := json.Unmarshal(rawMap[k], &listStringOrInt)
-			if err != nil {
-				return err
+			var values []any
+			err = dec.Decode(&values)
+			if m.Map == nil {
+				m.Map = make(map[string][]any)
 			}
-
-			internalMap[k] = listStringOrInt
+			m.Map[k] = values
+		}
+		if err != nil {
+			return fmt.Errorf("matrix %s: %w", k, err)
 		}
-	}
-
-	*m = Matrix{
-		Map:     internalMap,
-		Include: internalInclude,
-		Exclude: internalExclude,
 	}
 
 	return nil
@@ -440,6 +713,10 @@ func (m *Matrix) MarshalJSON() ([]byte, error) {
 		return []byte(util.JSONNull), nil
 	}
 
+	if m.Expression != "" {
+		return json.Marshal(m.Expression.String())
+	}
+
 	newMap := make(map[string]any)
 	for k, v := range m.Map {
 		newMap[k] = v
@@ -453,9 +730,14 @@ func (m *Matrix) MarshalJSON() ([]byte, error) {
 		newMap["exclude"] = m.Exclude
 	}
 
+	for k, expr := range m.KeyExpressions {
+		newMap[k] = expr.String()
+	}
+
 	return json.Marshal(newMap)
 }
 
+// StringOrInt is a string or a number, e.g. a port of a container, 80 or "8080:80"
 type StringOrInt struct {
 	StringValue *string
 	IntValue    *int
@@ -507,16 +789,14 @@ func (x *StringOrInt) UnmarshalJSON(data []byte) error {
 	var intVal int
 	if err := json.Unmarshal(data, &intVal); err == nil {
 		*x = StringOrInt{IntValue: &intVal}
+		return nil
 	}
 
 	return errors.New("invalid value in string or int")
 }
 
-func (x *StringOrInt) MarshalJSON() ([]byte, error) {
-	if x == nil {
-		return []byte(util.JSONNull), nil
-	}
-
+// MarshalJSON has a value receiver, so the values of Matrix maps, which are not addressable, use it too
+func (x StringOrInt) MarshalJSON() ([]byte, error) {
 	if x.IntValue != nil {
 		return json.Marshal(x.IntValue)
 	}
@@ -8,6 +8,8 @@ import (
 	"github.com/santhosh-tekuri/jsonschema/v6"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/cakehappens/gocto/expressions"
 )
 
 func TestWorkflowSchemaValidation(t *testing.T) {
@@ -142,3 +144,95 @@ func TestWorkflowSchemaValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestWorkflowValidate(t *testing.T) {
+	wf := Workflow{
+		Name: "invalid",
+		On: WorkflowOn{
+			Push: &OnPush{
+				OnBranches: &OnBranches{
+					Branches: []string{"main"},
+				},
+			},
+		},
+		Jobs: map[string]Job{
+			"build": {
+				RunsOn: StringOrSlice{"ubuntu-latest"},
+				Permissions: Permissions{
+					Contents: AccessLevel("admin"),
+				},
+				Steps: []Step{
+					{Run: "make"},
+					{Uses: "actions/checkout@v4", With: map[string]any{"fetch-depth": []int{1}}},
+				},
+			},
+		},
+	}
+
+	err := wf.Validate()
+	require.Error(t, err)
+
+	var paths []string
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var schemaErr *SchemaError
+		require.ErrorAs(t, e, &schemaErr)
+		paths = append(paths, schemaErr.Path)
+	}
+
+	assert.Contains(t, paths, `Jobs["build"].Permissions.Contents`)
+	assert.Contains(t, paths, `Jobs["build"].Steps[1].With["fetch-depth"]`)
+
+	wf.Jobs["build"] = Job{
+		RunsOn: StringOrSlice{"ubuntu-latest"},
+		Steps:  []Step{{Run: "make"}},
+	}
+	assert.NoError(t, wf.Validate())
+}
+
+// https://docs.github.com/en/actions/how-tos/write-workflows/choose-what-workflows-do/run-job-variations#example-expanding-configurations
+func TestMatrixCombinations(t *testing.T) {
+	m := &Matrix{
+		Map: map[string][]any{
+			"fruit":  {"apple", "pear"},
+			"animal": {"cat", "dog"},
+		},
+		Include: []map[string]any{
+			{"color": "green"},
+			{"color": "pink", "animal": "cat"},
+			{"fruit": "apple", "shape": "circle"},
+			{"fruit": "banana"},
+			{"fruit": "banana", "animal": "cat"},
+		},
+	}
+
+	assert.ElementsMatch(t, []map[string]any{
+		{"fruit": "apple", "animal": "cat", "color": "pink", "shape": "circle"},
+		{"fruit": "apple", "animal": "dog", "color": "green", "shape": "circle"},
+		{"fruit": "pear", "animal": "cat", "color": "pink"},
+		{"fruit": "pear", "animal": "dog", "color": "green"},
+		{"fruit": "banana"},
+		{"fruit": "banana", "animal": "cat"},
+	}, m.Combinations())
+
+	m.Include = nil
+	m.Exclude = []map[string]any{{"fruit": "pear", "animal": "dog"}}
+	assert.Len(t, m.Combinations(), 3)
+}
+
+func TestMatrixCombinationsObjects(t *testing.T) {
+	linux := map[string]any{"os": "linux", "arch": json.Number("64")}
+	m := &Matrix{
+		Map: map[string][]any{
+			"platform": {linux, map[string]any{"os": "darwin", "arch": "arm64"}},
+			"go":       {"1.25"},
+		},
+		Exclude: []map[string]any{{"platform": map[string]any{"os": "darwin", "arch": "arm64"}}},
+	}
+
+	assert.Equal(t, []map[string]any{
+		{"platform": map[string]any{"os": "linux", "arch": 64}, "go": "1.25"},
+	}, m.Combinations())
+
+	m.KeyExpressions = map[string]expressions.Expression{"go": "fromJSON(inputs.go)"}
+	assert.Nil(t, m.Combinations())
+}
@@ -0,0 +1,29 @@
+package gocto
+
+import (
+	"encoding/json"
+
+	"github.com/cakehappens/gocto/internal/util"
+)
+
+// MarshalYAML renders v as YAML through its JSON representation,
+// so the json struct tags and custom marshallers decide the document shape
+func MarshalYAML(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	node, err := util.JSONToYAMLNode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return util.EncodeYAML(node)
+}
+
+// Render returns the YAML document for the workflow,
+// as it would be written to GetRelativePathAndFilename
+func (w Workflow) Render() ([]byte, error) {
+	return MarshalYAML(w)
+}